@@ -0,0 +1,19 @@
+package siteperf
+
+import "strings"
+
+// DefaultAMPPrefixes are the prefixes of the classes that the AMP runtime adds
+// to the elements of AMP pages, like "i-amphtml-layout-responsive",
+// "-amp-layout-size-defined", or "amp-mode-mouse".
+var DefaultAMPPrefixes = []string{"i-amphtml-", "-amp-", "amp-"}
+
+// isAMPClass reports whether the class has one of the excluded AMP prefixes
+// (see [WithExcludeAMPClasses]).
+func (f *Finder) isAMPClass(class string) bool {
+	for _, prefix := range f.ampPrefixes {
+		if strings.HasPrefix(class, prefix) {
+			return true
+		}
+	}
+	return false
+}
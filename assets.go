@@ -0,0 +1,140 @@
+package siteperf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"sync"
+
+	"github.com/go-rod/rod"
+)
+
+// AssetUsage crawls the website of the Finder and returns the absolute URLs of
+// all assets that are referenced by the crawled pages, sorted and without
+// duplicates. Referenced assets are the sources of images (including every
+// candidate of "srcset" attributes), videos, audio, and icons, as well as the
+// images referenced by the computed styles of all elements and their ::before
+// and ::after pseudo-elements, e.g. using "background-image: url(...)". Use
+// [UnusedAssets] to find the existing assets that are never referenced.
+func (f *Finder) AssetUsage(ctx context.Context) ([]string, error) {
+	var (
+		mux        sync.Mutex
+		referenced []string
+	)
+
+	_, err := f.crawl(ctx, func(ctx context.Context, page *rod.Page, result *pageResult) error {
+		assets, err := extractAssets(page, result.url)
+		if err != nil {
+			return fmt.Errorf("extract assets: %w", err)
+		}
+
+		mux.Lock()
+		defer mux.Unlock()
+		referenced = append(referenced, assets...)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("find referenced assets: %w", err)
+	}
+
+	referenced = unique(referenced)
+	slices.Sort(referenced)
+
+	return referenced, nil
+}
+
+// UnusedAssets returns the existing assets that are not referenced, in the same
+// order as they were provided. Existing assets may be given as absolute URLs
+// or as URL paths like "/images/logo.png", which match a referenced asset with
+// that path on any host.
+func UnusedAssets(existing, referenced []string) []string {
+	refs := make(map[string]bool, 2*len(referenced))
+	for _, ref := range referenced {
+		refs[ref] = true
+		if u, err := url.Parse(ref); err == nil {
+			refs[u.Path] = true
+		}
+	}
+
+	return filter(existing, func(asset string) bool {
+		return !refs[asset]
+	})
+}
+
+// extractAssets returns the absolute HTTP(S) URLs of the assets referenced by
+// the page.
+func extractAssets(page *rod.Page, pageUrl string) ([]string, error) {
+	res, err := page.Eval(`() => {
+		const out = []
+		const add = (value) => {
+			if (value && value.trim()) {
+				out.push(value.trim())
+			}
+		}
+		const addSrcset = (value) => {
+			if (!value) {
+				return
+			}
+			for (const candidate of value.split(/,\s+/)) {
+				add(candidate.trim().split(/\s+/)[0])
+			}
+		}
+		const addStyleURLs = (style) => {
+			for (const prop of ["background-image", "list-style-image", "border-image-source", "mask-image", "content"]) {
+				const value = style.getPropertyValue(prop)
+				if (!value || !value.includes("url(")) {
+					continue
+				}
+				for (const match of value.matchAll(/url\(\s*(['"]?)(.*?)\1\s*\)/g)) {
+					add(match[2])
+				}
+			}
+		}
+
+		for (const el of document.querySelectorAll("img, source, video, audio, track, input[type=image]")) {
+			add(el.getAttribute("src"))
+			addSrcset(el.getAttribute("srcset"))
+			add(el.getAttribute("poster"))
+		}
+		for (const el of document.querySelectorAll("link[rel~=icon], link[rel=apple-touch-icon], link[rel=preload][as=image]")) {
+			add(el.getAttribute("href"))
+			addSrcset(el.getAttribute("imagesrcset"))
+		}
+		for (const el of document.querySelectorAll("*")) {
+			addStyleURLs(getComputedStyle(el))
+			addStyleURLs(getComputedStyle(el, "::before"))
+			addStyleURLs(getComputedStyle(el, "::after"))
+		}
+
+		return out
+	}`)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw []string
+	if err := json.Unmarshal([]byte(res.Value.JSON("", "")), &raw); err != nil {
+		return nil, fmt.Errorf("decode asset URLs: %w", err)
+	}
+
+	base, err := url.Parse(pageUrl)
+	if err != nil {
+		return nil, fmt.Errorf("parse page URL: %w", err)
+	}
+
+	out := make([]string, 0, len(raw))
+	for _, ref := range raw {
+		u, err := base.Parse(ref)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			continue
+		}
+		u.Fragment = ""
+		u.RawFragment = ""
+		out = append(out, u.String())
+	}
+
+	return unique(out), nil
+}
@@ -0,0 +1,116 @@
+package siteperf
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-rod/rod"
+)
+
+// AttributeSelector is an attribute selector referenced by a stylesheet, like
+// [aria-expanded="true"] or [data-state]. Many accessible components are
+// styled through attributes that are toggled by scripts instead of through
+// state classes.
+type AttributeSelector struct {
+	// Name is the lowercased name of the attribute.
+	Name string `json:"name"`
+
+	// Operator is the matching operator ("=", "~=", "|=", "^=", "$=", "*="),
+	// or empty if the selector only checks for the presence of the attribute.
+	Operator string `json:"operator,omitempty"`
+
+	// Value is the unquoted value that the attribute is matched against.
+	Value string `json:"value,omitempty"`
+}
+
+// String returns the attribute selector in CSS syntax, e.g.
+// [aria-expanded="true"].
+func (s AttributeSelector) String() string {
+	if s.Operator == "" {
+		return "[" + s.Name + "]"
+	}
+	value := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s.Value)
+	return "[" + s.Name + s.Operator + `"` + value + `"]`
+}
+
+// ExtractAttributeSelectors parses the provided CSS and returns the unique
+// attribute selectors referenced by its style rules, sorted by their CSS
+// representation. The result can be passed to [WithAttributeSelectors] to
+// check which of the attribute selectors match any element of the crawled
+// pages.
+func ExtractAttributeSelectors(css string) ([]AttributeSelector, error) {
+	sheet := parseStylesheet(css)
+
+	var out []AttributeSelector
+	for _, rule := range sheet.rules {
+		for _, sel := range parseSelectorList(rule.selector) {
+			out = append(out, selectorAttrs(sel)...)
+		}
+	}
+
+	out = unique(out)
+	slices.SortFunc(out, func(a, b AttributeSelector) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	return out, nil
+}
+
+func selectorAttrs(sel complexSelector) []AttributeSelector {
+	var out []AttributeSelector
+	for _, compound := range sel.compounds {
+		for _, attr := range compound.attrs {
+			if attr.name == "" {
+				continue
+			}
+			out = append(out, AttributeSelector{
+				Name:     attr.name,
+				Operator: attr.operator,
+				Value:    attr.value,
+			})
+		}
+		for _, ps := range compound.pseudos {
+			for _, inner := range ps.selectors {
+				out = append(out, selectorAttrs(inner)...)
+			}
+		}
+	}
+	return out
+}
+
+// countAttributeSelectors returns the number of elements on the page that
+// match each of the configured attribute selectors, keyed by the CSS
+// representation of the selector.
+func (f *Finder) countAttributeSelectors(page *rod.Page) (map[string]int, error) {
+	selectors := make([]string, len(f.attributeSelectors))
+	for i, sel := range f.attributeSelectors {
+		selectors[i] = sel.String()
+	}
+
+	res, err := page.Eval(`(selectors) => selectors.map((selector) => {
+		try {
+			return document.querySelectorAll(selector).length
+		} catch {
+			return 0
+		}
+	})`, selectors)
+	if err != nil {
+		return nil, fmt.Errorf("count elements matching attribute selectors: %w", err)
+	}
+
+	var counts []int
+	if err := json.Unmarshal([]byte(res.Value.JSON("", "")), &counts); err != nil {
+		return nil, fmt.Errorf("decode attribute selector counts: %w", err)
+	}
+
+	out := make(map[string]int, len(selectors))
+	for i, sel := range selectors {
+		if i < len(counts) && counts[i] > 0 {
+			out[sel] = counts[i]
+		}
+	}
+
+	return out, nil
+}
@@ -0,0 +1,331 @@
+package siteperf
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Audit is the detailed result of checking a set of CSS classes against the
+// pages of a website. In addition to the unused classes reported by
+// [Finder.FindUnused], it contains the usage count of every class found on the
+// crawled pages and a categorization of the classes that need special
+// attention before they are removed.
+type Audit struct {
+	// Unused contains the provided classes that were not found on any of the
+	// crawled pages.
+	Unused []string `json:"unused"`
+
+	// Undefined contains the classes that were found on the crawled pages, but
+	// are not among the provided classes, sorted by name.
+	Undefined []string `json:"undefined"`
+
+	// Usage maps every class found on the crawled pages to the number of
+	// elements it was found on.
+	Usage map[string]int `json:"usage"`
+
+	// Tags maps every class found on the crawled pages to the sorted tag
+	// names of the elements it was found on, e.g. ["a", "button"]. A class
+	// that is only ever found on a single kind of element is usually safer to
+	// refactor than one that is scattered across many.
+	Tags map[string][]string `json:"tags,omitempty"`
+
+	// Coverage summarizes how many of the provided classes are used.
+	Coverage CoverageStats `json:"coverage"`
+
+	// Crawl contains the stats of the crawl the audit is based on.
+	Crawl CrawlStats `json:"crawl"`
+
+	// RootOnly contains the provided classes that are used, but were only ever
+	// found on the root <html> or <body> element. These are often theme
+	// toggles like ".dark" that are applied by scripts and are easily missed
+	// when the audit is scoped to specific elements.
+	RootOnly []string `json:"rootOnly"`
+
+	// HiddenOnly contains the provided classes that were only found on
+	// elements within hidden subtrees. It is only populated if hidden elements
+	// are ignored (see [WithIgnoreHidden]), in which case these classes are
+	// also reported as unused.
+	HiddenOnly []string `json:"hiddenOnly,omitempty"`
+
+	// UnusedVariants maps every used base class to its defined variants that
+	// are unused, e.g. "hidden" to ["lg:hidden", "print:hidden"] (see
+	// [AnalyzeVariants]). Base classes without unused variants are omitted.
+	UnusedVariants map[string][]string `json:"unusedVariants,omitempty"`
+
+	// NonScreenOnly contains the provided classes that are only referenced
+	// within @media rules for non-screen media like "print". It is only
+	// populated by [Finder.AuditDetailed]. Unless configured otherwise (see
+	// [WithIncludeNonScreen]), these classes are excluded from Unused because
+	// a crawl of the rendered pages cannot detect their usage.
+	NonScreenOnly []string `json:"nonScreenOnly,omitempty"`
+
+	// NegatedOnly contains the provided classes that are only referenced
+	// within the arguments of :not() pseudo-classes, like "hidden" in
+	// "button:not(.hidden)". It is only populated by [Finder.AuditDetailed].
+	// These classes are still reported as unused if they are not found on any
+	// page, but removing them from the stylesheet changes which elements the
+	// :not() selectors match, so they must be reviewed before removal.
+	NegatedOnly []string `json:"negatedOnly,omitempty"`
+
+	// Duplicates maps the classes that are defined in more than one of the
+	// audited files to the paths of these files. It is only populated by
+	// [Finder.AuditFiles].
+	Duplicates map[string][]string `json:"duplicates,omitempty"`
+
+	// Conflicts maps the classes that are defined in more than one of the
+	// audited files to the declarations of these files that set the same
+	// property of the same selector to different values (see
+	// [FindDeclarationConflicts]). It is only populated by
+	// [Finder.AuditFiles].
+	Conflicts map[string][]DeclarationConflict `json:"conflicts,omitempty"`
+
+	// WeightedUsage maps every class found on the crawled pages to its
+	// weighted usage score. It is only populated if page weights are
+	// configured (see [WithPageWeights]).
+	WeightedUsage map[string]float64 `json:"weightedUsage,omitempty"`
+
+	// AttributeUsage maps each configured attribute selector (see
+	// [WithAttributeSelectors]) to the number of elements it matched on the
+	// crawled pages.
+	AttributeUsage map[string]int `json:"attributeUsage,omitempty"`
+
+	// UnusedAttributes contains the configured attribute selectors that did
+	// not match any element on the crawled pages.
+	UnusedAttributes []string `json:"unusedAttributes,omitempty"`
+
+	// Diagnostics contains the likely problems with the setup of the audit.
+	// It is only populated if none of the defined classes was found on the
+	// crawled pages, in which case the unused classes should not be trusted.
+	Diagnostics []Diagnostic `json:"diagnostics,omitempty"`
+}
+
+// Audit crawls the website of the Finder and returns a detailed [Audit] of the
+// provided CSS classes. The returned Audit contains the unused classes, the
+// usage count of every class found on the crawled pages, and the used classes
+// that were only found on the root <html> or <body> element.
+func (f *Finder) Audit(ctx context.Context, classes []string) (Audit, error) {
+	result, err := f.crawl(ctx)
+	if err != nil {
+		return Audit{}, fmt.Errorf("find used classes: %w", err)
+	}
+
+	return f.auditResult(classes, result), nil
+}
+
+// auditResult returns the audit of the provided classes against the result of
+// a crawl.
+func (f *Finder) auditResult(classes []string, result *crawlResult) Audit {
+	audit := f.newAudit(classes, result.used())
+	audit.Crawl = result.summary.stats()
+	audit.Diagnostics = audit.diagnose()
+	for _, d := range audit.Diagnostics {
+		f.log.Warn("Audit result is likely wrong", "code", d.Code, "diagnostic", d.Message)
+	}
+	if f.pageWeights != nil {
+		audit.WeightedUsage = f.weightedUsage(result)
+	}
+
+	if len(f.attributeSelectors) > 0 {
+		audit.AttributeUsage = make(map[string]int)
+		for _, page := range result.pages {
+			for sel, count := range page.attributes {
+				audit.AttributeUsage[sel] += count
+			}
+		}
+		for _, sel := range f.attributeSelectors {
+			if audit.AttributeUsage[sel.String()] == 0 {
+				audit.UnusedAttributes = append(audit.UnusedAttributes, sel.String())
+			}
+		}
+		audit.UnusedAttributes = unique(audit.UnusedAttributes)
+	}
+
+	return audit
+}
+
+// AuditDetailed works like [Finder.Audit], but audits the classes of a
+// detailed CSS extraction (see [ExtractClassesDetailed]). Classes that are only
+// referenced within @media rules for non-screen media like "print" are
+// reported in the NonScreenOnly field of the returned Audit and, by default,
+// excluded from its Unused classes. Classes that are only referenced within
+// :not() pseudo-classes are reported in its NegatedOnly field.
+func (f *Finder) AuditDetailed(ctx context.Context, details []ClassDetails) (Audit, error) {
+	classes := make([]string, 0, len(details))
+	var nonScreen, negated []string
+	for _, d := range details {
+		classes = append(classes, d.Name)
+		if d.NonScreenOnly() {
+			nonScreen = append(nonScreen, d.Name)
+		}
+		if d.NegatedOnly() {
+			negated = append(negated, d.Name)
+		}
+	}
+
+	audit, err := f.Audit(ctx, classes)
+	if err != nil {
+		return audit, err
+	}
+
+	audit.NonScreenOnly = nonScreen
+	audit.NegatedOnly = negated
+	if !f.includeNonScreen {
+		screen := func(class string) bool {
+			return !slices.Contains(nonScreen, class)
+		}
+		audit.Unused = filter(audit.Unused, screen)
+		audit.Coverage = f.coverage(filter(classes, screen), audit.Usage)
+	}
+
+	return audit, nil
+}
+
+// AuditFiles extracts the classes of the given CSS files using
+// [ExtractClassesDetailed] and audits them like [Finder.AuditDetailed]. In
+// addition, the returned Audit reports the classes that are defined in more
+// than one of the files, and the conflicting declarations of these classes.
+func (f *Finder) AuditFiles(ctx context.Context, paths ...string) (Audit, error) {
+	var (
+		details []ClassDetails
+		sources []cssSource
+	)
+	definedIn := make(map[string][]string)
+
+	for _, path := range paths {
+		css, err := os.ReadFile(path)
+		if err != nil {
+			return Audit{}, err
+		}
+
+		fileDetails, err := ExtractClassesDetailed(string(css))
+		if err != nil {
+			return Audit{}, fmt.Errorf("extract classes from %q: %w", path, err)
+		}
+
+		for _, d := range fileDetails {
+			definedIn[d.Name] = append(definedIn[d.Name], path)
+		}
+		details = mergeClassDetails(details, fileDetails)
+		sources = append(sources, cssSource{path: path, css: string(css)})
+	}
+
+	audit, err := f.AuditDetailed(ctx, details)
+	if err != nil {
+		return audit, err
+	}
+
+	for class, files := range definedIn {
+		if files = unique(files); len(files) > 1 {
+			if audit.Duplicates == nil {
+				audit.Duplicates = make(map[string][]string)
+			}
+			audit.Duplicates[class] = files
+		}
+	}
+
+	if conflicts := declarationConflicts(sources); len(conflicts) > 0 {
+		audit.Conflicts = conflicts
+	}
+
+	return audit, nil
+}
+
+// mergeClassDetails merges the references of classes with the same name and
+// returns the merged details sorted by class name.
+func mergeClassDetails(a, b []ClassDetails) []ClassDetails {
+	merged := make(map[string]ClassDetails, len(a)+len(b))
+	for _, d := range append(append([]ClassDetails(nil), a...), b...) {
+		m := merged[d.Name]
+		m.Name = d.Name
+		m.References = append(m.References, d.References...)
+		merged[d.Name] = m
+	}
+
+	out := make([]ClassDetails, 0, len(merged))
+	for _, d := range merged {
+		out = append(out, d)
+	}
+	slices.SortFunc(out, func(a, b ClassDetails) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return out
+}
+
+// UnusedPage returns at most limit unused classes of the audit, starting at
+// the given offset, which allows to page through long lists of unused
+// classes. A limit of 0 or less returns all classes after the offset. Because
+// the order of the unused classes is stable, consecutive pages do not overlap.
+// The returned page is never nil, even if it is empty.
+func (a Audit) UnusedPage(offset, limit int) []string {
+	if a.Unused == nil {
+		return []string{}
+	}
+	offset = min(max(offset, 0), len(a.Unused))
+	end := len(a.Unused)
+	if limit > 0 {
+		end = min(offset+limit, end)
+	}
+	return a.Unused[offset:end]
+}
+
+func (f *Finder) newAudit(classes []string, used []usedClass) Audit {
+	// The lists that are always encoded are empty instead of nil, so that they
+	// are encoded as [] instead of null.
+	audit := Audit{
+		Undefined: []string{},
+		RootOnly:  []string{},
+		Usage:     make(map[string]int, len(used)),
+		Tags:      make(map[string][]string, len(used)),
+	}
+
+	defined := make(map[string]bool, len(classes))
+	for _, class := range classes {
+		defined[f.foldClass(class)] = true
+	}
+
+	for _, uc := range used {
+		isDefined := defined[f.foldClass(uc.class)]
+		if uc.hiddenOnly() {
+			if isDefined {
+				audit.HiddenOnly = append(audit.HiddenOnly, uc.class)
+			}
+			continue
+		}
+
+		audit.Usage[uc.class] = uc.count
+		audit.Tags[uc.class] = uc.tags
+		if !isDefined {
+			audit.Undefined = append(audit.Undefined, uc.class)
+		}
+		if uc.rootOnly() && isDefined {
+			audit.RootOnly = append(audit.RootOnly, uc.class)
+		}
+	}
+	slices.Sort(audit.Undefined)
+	slices.Sort(audit.RootOnly)
+	slices.Sort(audit.HiddenOnly)
+
+	// Ignored classes are still defined, so they are not reported as
+	// undefined, but they are not audited.
+	audited := f.withoutIgnored(classes)
+
+	if audit.Unused = f.FindUnusedFromUsed(audit.Usage, audited); audit.Unused == nil {
+		audit.Unused = []string{}
+	}
+	audit.Coverage = f.coverage(audited, audit.Usage)
+
+	for _, v := range AnalyzeVariants(audited, audit.Usage) {
+		if v.BaseUsed && len(v.UnusedVariants) > 0 {
+			if audit.UnusedVariants == nil {
+				audit.UnusedVariants = make(map[string][]string)
+			}
+			audit.UnusedVariants[v.Base] = v.UnusedVariants
+		}
+	}
+
+	return audit
+}
@@ -0,0 +1,74 @@
+package siteperf
+
+import (
+	"hash/maphash"
+	"math"
+)
+
+// bloomFilter is a probabilistic set of strings (see [WithBloomVisited]). It
+// never reports an added string as missing, but may report a string that was
+// never added as present, at a false-positive rate that depends on the number
+// of added strings.
+type bloomFilter struct {
+	bits []uint64
+
+	// m is the number of bits and k the number of hash functions.
+	m uint64
+	k uint64
+}
+
+// newBloomFilter returns a bloom filter that is sized for n strings at the
+// given false-positive rate.
+func newBloomFilter(n int, fpRate float64) *bloomFilter {
+	n = max(n, 1)
+	if fpRate <= 0 || fpRate >= 1 {
+		fpRate = defaultBloomFPRate
+	}
+
+	// The optimal number of bits and hash functions for n elements at the
+	// false-positive rate p are m = -n*ln(p)/ln(2)² and k = m/n*ln(2).
+	m := uint64(math.Ceil(-float64(n) * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	m = max(m, 64)
+	k := uint64(math.Round(float64(m) / float64(n) * math.Ln2))
+	k = max(k, 1)
+
+	return &bloomFilter{
+		bits: make([]uint64, (m+63)/64),
+		m:    m,
+		k:    k,
+	}
+}
+
+// defaultBloomFPRate is the false-positive rate of a bloom filter that is
+// configured with an invalid rate.
+const defaultBloomFPRate = 0.001
+
+func (b *bloomFilter) add(s string) {
+	h1, h2 := bloomHashes(s)
+	for i := range b.k {
+		bit := (h1 + i*h2) % b.m
+		b.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+func (b *bloomFilter) has(s string) bool {
+	h1, h2 := bloomHashes(s)
+	for i := range b.k {
+		bit := (h1 + i*h2) % b.m
+		if b.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// bloomSeeds are the seeds of the two independent hashes of bloomHashes. The
+// filter is never persisted, so the hashes only need to be stable within the
+// process.
+var bloomSeeds = [2]maphash.Seed{maphash.MakeSeed(), maphash.MakeSeed()}
+
+// bloomHashes returns the two hashes of s from which the k hashes of the
+// filter are derived by double hashing.
+func bloomHashes(s string) (uint64, uint64) {
+	return maphash.String(bloomSeeds[0], s), maphash.String(bloomSeeds[1], s)
+}
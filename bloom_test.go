@@ -0,0 +1,84 @@
+package siteperf
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestBloomFilter(t *testing.T) {
+	const n = 100000
+
+	for _, fpRate := range []float64{0.01, 0.001} {
+		t.Run(fmt.Sprintf("fpRate=%g", fpRate), func(t *testing.T) {
+			b := newBloomFilter(n, fpRate)
+			for i := 0; i < n; i++ {
+				b.add(fmt.Sprintf("/blog/posts/%d", i))
+			}
+
+			for i := 0; i < n; i++ {
+				if key := fmt.Sprintf("/blog/posts/%d", i); !b.has(key) {
+					t.Fatalf("has(%q) = false after add(%q)", key, key)
+				}
+			}
+
+			// The measured rate varies with the hashes, so it is only
+			// expected to be close to the configured rate.
+			const probes = 10 * n
+			var falsePositives int
+			for i := 0; i < probes; i++ {
+				if b.has(fmt.Sprintf("/docs/pages/%d", i)) {
+					falsePositives++
+				}
+			}
+			rate := float64(falsePositives) / probes
+			t.Logf("false-positive rate: %g", rate)
+			if rate > 1.5*fpRate {
+				t.Errorf("false-positive rate is %g, want at most %g", rate, 1.5*fpRate)
+			}
+		})
+	}
+}
+
+func TestNewBloomFilter_invalidRate(t *testing.T) {
+	for _, fpRate := range []float64{0, -1, 1, 2} {
+		got := newBloomFilter(1000, fpRate)
+		want := newBloomFilter(1000, defaultBloomFPRate)
+		if got.m != want.m || got.k != want.k {
+			t.Errorf("newBloomFilter(1000, %g) has m=%d k=%d, want m=%d k=%d", fpRate, got.m, got.k, want.m, want.k)
+		}
+	}
+}
+
+// BenchmarkVisitedPages compares the memory of the visited pages of a crawl
+// of 100,000 pages that are tracked by the exact set and by a bloom filter
+// (see [WithBloomVisited]). The memory is reported as B/op.
+func BenchmarkVisitedPages(b *testing.B) {
+	const pages = 100000
+	keys := make([]string, pages)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("/products/category-%d/item-%d", i%100, i)
+	}
+
+	for _, bloom := range []bool{false, true} {
+		b.Run(fmt.Sprintf("bloom=%t", bloom), func(b *testing.B) {
+			var opts []Option
+			if bloom {
+				opts = append(opts, WithBloomVisited(pages, 0.001))
+			}
+			f, err := New("https://example.com", 0, opts...)
+			if err != nil {
+				b.Fatalf("New() failed: %v", err)
+			}
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				visited := f.newVisitedPages()
+				for _, key := range keys {
+					if !visited.has(key) {
+						visited.add(key)
+					}
+				}
+			}
+		})
+	}
+}
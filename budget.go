@@ -0,0 +1,39 @@
+package siteperf
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// extractBudget is the time budget of the extraction of the pages of a crawl
+// (see [WithExtractTimeout]). Once it is exhausted, pages are only rendered to
+// discover their links. A nil *extractBudget is never exhausted.
+type extractBudget struct {
+	deadline time.Time
+	skipped  atomic.Int64
+}
+
+// newExtractBudget returns the extraction budget of a crawl that starts now,
+// or nil if the extraction time is not limited.
+func (f *Finder) newExtractBudget() *extractBudget {
+	if f.extractTimeout <= 0 {
+		return nil
+	}
+	return &extractBudget{deadline: time.Now().Add(f.extractTimeout)}
+}
+
+// exhausted reports whether the budget is exhausted. If it is, the caller is
+// expected to skip the extraction of its page, which is counted.
+func (b *extractBudget) exhausted() bool {
+	if b == nil || time.Now().Before(b.deadline) {
+		return false
+	}
+	b.skipped.Add(1)
+	return true
+}
+
+// exceeded reports whether the extraction of any page was skipped because the
+// budget was exhausted.
+func (b *extractBudget) exceeded() bool {
+	return b != nil && b.skipped.Load() > 0
+}
@@ -0,0 +1,266 @@
+package siteperf
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// pageCache stores the results of rendered pages on disk, keyed by page URL
+// and the configuration of the extraction. A cached result is only used if a
+// conditional GET request for the page indicates that its content has not
+// changed since the result was cached.
+type pageCache struct {
+	dir string
+
+	// config is the fingerprint of the options that change the extracted
+	// data of a page (see [Finder.extractionConfig]). Results that were
+	// extracted with different options are never used.
+	config string
+}
+
+// cacheValidator identifies the content of a page at the time it was cached.
+type cacheValidator struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	ContentHash  string `json:"contentHash,omitempty"`
+}
+
+func (v cacheValidator) empty() bool {
+	return v == cacheValidator{}
+}
+
+type cacheEntry struct {
+	URL          string         `json:"url"`
+	Config       string         `json:"config,omitempty"`
+	Validator    cacheValidator `json:"validator"`
+	FinalURL     string         `json:"finalUrl,omitempty"`
+	Status       int            `json:"status,omitempty"`
+	LoadTime     time.Duration  `json:"loadTime,omitempty"`
+	Classes      []cachedClass  `json:"classes"`
+	Stylesheets  []string       `json:"stylesheets,omitempty"`
+	InlineStyles []string       `json:"inlineStyles,omitempty"`
+	Attributes   map[string]int `json:"attributes,omitempty"`
+	Links        []string       `json:"links,omitempty"`
+}
+
+type cachedClass struct {
+	Class     string `json:"class"`
+	Count     int    `json:"count"`
+	RootCount int    `json:"rootCount,omitempty"`
+
+	HiddenCount int      `json:"hiddenCount,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// lookup checks whether the page with the given URL has changed since it was
+// cached. It returns the validator of the current content of the page, which
+// should be stored along with a freshly rendered result, and the cached result
+// if the page is unchanged.
+func (c *pageCache) lookup(ctx context.Context, client *http.Client, header http.Header, maxSize int64, pageUrl string) (cacheValidator, pageResult, bool) {
+	entry, err := c.load(pageUrl)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return cacheValidator{}, pageResult{}, false
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageUrl, nil)
+	if err != nil {
+		return cacheValidator{}, pageResult{}, false
+	}
+	req.Header = header
+	if entry != nil {
+		if entry.Validator.ETag != "" {
+			req.Header.Set("If-None-Match", entry.Validator.ETag)
+		}
+		if entry.Validator.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.Validator.LastModified)
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return cacheValidator{}, pageResult{}, false
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		return entry.Validator, entry.result(), true
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return cacheValidator{}, pageResult{}, false
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, limitBody(resp.Body, maxSize)); err != nil {
+		return cacheValidator{}, pageResult{}, false
+	}
+
+	validator := cacheValidator{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		ContentHash:  hex.EncodeToString(hash.Sum(nil)),
+	}
+
+	if entry != nil && entry.Validator.ContentHash == validator.ContentHash {
+		return validator, entry.result(), true
+	}
+
+	return validator, pageResult{}, false
+}
+
+// store caches the result of the page with the given URL. Results without a
+// validator are not cached because they could never be validated.
+func (c *pageCache) store(pageUrl string, validator cacheValidator, result pageResult) error {
+	if validator.empty() {
+		return nil
+	}
+
+	entry := cacheEntry{
+		URL:          pageUrl,
+		Config:       c.config,
+		Validator:    validator,
+		FinalURL:     result.finalURL,
+		Status:       result.status,
+		LoadTime:     result.loadTime,
+		Stylesheets:  result.stylesheets,
+		InlineStyles: result.inlineStyles,
+		Attributes:   result.attributes,
+	}
+	for _, class := range result.classes {
+		entry.Classes = append(entry.Classes, cachedClass{
+			Class:     class.class,
+			Count:     class.count,
+			RootCount: class.rootCount,
+
+			HiddenCount: class.hiddenCount,
+			Tags:        class.tags,
+		})
+	}
+	for _, link := range result.links {
+		entry.Links = append(entry.Links, link.String())
+	}
+
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode cache entry: %w", err)
+	}
+
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("create cache directory: %w", err)
+	}
+
+	return os.WriteFile(c.path(pageUrl), b, 0o644)
+}
+
+func (c *pageCache) load(pageUrl string) (*cacheEntry, error) {
+	b, err := os.ReadFile(c.path(pageUrl))
+	if err != nil {
+		return nil, err
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(b, &entry); err != nil {
+		return nil, fmt.Errorf("decode cache entry: %w", err)
+	}
+	if entry.Config != c.config {
+		return nil, fmt.Errorf("cache entry of %q was extracted with other options: %w", pageUrl, fs.ErrNotExist)
+	}
+
+	return &entry, nil
+}
+
+func (c *pageCache) path(pageUrl string) string {
+	key := pageUrl
+	if c.config != "" {
+		key = c.config + " " + pageUrl
+	}
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// extractionConfig returns a fingerprint of the options that change the data
+// that is extracted from a page, so that the page cache does not mix results
+// of crawls with different options. Options that are applied to the results
+// after they are cached, like the class manifest, are not part of it.
+func (f *Finder) extractionConfig() string {
+	config := struct {
+		ScanInlineStyles   bool     `json:"scanInlineStyles,omitempty"`
+		AttributeSelectors []string `json:"attributeSelectors,omitempty"`
+		ScriptMarkupTypes  []string `json:"scriptMarkupTypes,omitempty"`
+		RespectNofollow    bool     `json:"respectNofollow,omitempty"`
+		IgnoreHidden       bool     `json:"ignoreHidden,omitempty"`
+		DropInvalidClasses bool     `json:"dropInvalidClasses,omitempty"`
+		PrepareScripts     []string `json:"prepareScripts,omitempty"`
+		ExpandAll          bool     `json:"expandAll,omitempty"`
+		AcceptLanguage     string   `json:"acceptLanguage,omitempty"`
+		AMPPrefixes        []string `json:"ampPrefixes,omitempty"`
+		SVGUseReferences   bool     `json:"svgUseReferences,omitempty"`
+		IncludeFrames      bool     `json:"includeFrames,omitempty"`
+		RoleSelector       string   `json:"roleSelector,omitempty"`
+	}{
+		ScanInlineStyles:   f.scanInlineStyles,
+		ScriptMarkupTypes:  f.scriptMarkupTypes,
+		RespectNofollow:    f.respectNofollow,
+		IgnoreHidden:       f.ignoreHidden,
+		DropInvalidClasses: f.dropInvalidClasses,
+		PrepareScripts:     f.prepareScripts,
+		ExpandAll:          f.expandAll,
+		AcceptLanguage:     f.acceptLanguage,
+		AMPPrefixes:        f.ampPrefixes,
+		SVGUseReferences:   f.svgUseReferences,
+		IncludeFrames:      f.includeFrames,
+		RoleSelector:       f.roleSelector,
+	}
+	for _, sel := range f.attributeSelectors {
+		config.AttributeSelectors = append(config.AttributeSelectors, sel.String())
+	}
+
+	// The config only contains strings and booleans, which always encode.
+	b, _ := json.Marshal(config)
+	if string(b) == "{}" {
+		// Entries of crawls with the default options keep the key of the
+		// cache entries of earlier versions.
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:8])
+}
+
+func (e *cacheEntry) result() pageResult {
+	result := pageResult{
+		url:          e.URL,
+		finalURL:     e.FinalURL,
+		status:       e.Status,
+		loadTime:     e.LoadTime,
+		stylesheets:  e.Stylesheets,
+		inlineStyles: e.InlineStyles,
+		attributes:   e.Attributes,
+	}
+	for _, class := range e.Classes {
+		result.classes = append(result.classes, usedClass{
+			class:     class.Class,
+			count:     class.Count,
+			rootCount: class.RootCount,
+
+			hiddenCount: class.HiddenCount,
+			tags:        class.Tags,
+		})
+	}
+	for _, link := range e.Links {
+		if u, err := url.Parse(link); err == nil {
+			result.links = append(result.links, u)
+		}
+	}
+	return result
+}
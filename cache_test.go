@@ -0,0 +1,94 @@
+package siteperf
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"maps"
+	"testing"
+)
+
+func TestPageCache_config(t *testing.T) {
+	dir := t.TempDir()
+	const pageUrl = "https://example.com/"
+
+	filtered := &pageCache{dir: dir, config: "filtered"}
+	result := pageResult{url: pageUrl, classes: []usedClass{{class: "btn", count: 1}}}
+	if err := filtered.store(pageUrl, cacheValidator{ETag: `"1"`}, result); err != nil {
+		t.Fatalf("store() failed: %v", err)
+	}
+
+	if _, err := filtered.load(pageUrl); err != nil {
+		t.Errorf("load() with the same config failed: %v", err)
+	}
+
+	for _, config := range []string{"", "other"} {
+		other := &pageCache{dir: dir, config: config}
+		if _, err := other.load(pageUrl); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("load() with config %q returned %v, want %v", config, err, fs.ErrNotExist)
+		}
+	}
+}
+
+func TestFinder_extractionConfig(t *testing.T) {
+	config := func(opts ...Option) string {
+		t.Helper()
+		f, err := New("https://example.com", 0, opts...)
+		if err != nil {
+			t.Fatalf("New() failed: %v", err)
+		}
+		return f.extractionConfig()
+	}
+
+	if got := config(); got != "" {
+		t.Errorf("config of the default options is %q, want %q", got, "")
+	}
+	if got := config(WithClassManifest(map[string]string{"btn": "a1"})); got != "" {
+		t.Errorf("config with a class manifest is %q, want %q", got, "")
+	}
+
+	configs := map[string]string{
+		"role filter":   config(WithRoleFilter([]string{"button"})),
+		"other roles":   config(WithRoleFilter([]string{"link"})),
+		"ignore hidden": config(WithIgnoreHidden(true)),
+		"frames":        config(WithIncludeFrames(true)),
+		"svg":           config(WithSVGUseReferences(true)),
+		"amp":           config(WithExcludeAMPClasses()),
+	}
+	seen := make(map[string]string)
+	for name, c := range configs {
+		if c == "" {
+			t.Errorf("config with %s is empty", name)
+		}
+		if other, ok := seen[c]; ok {
+			t.Errorf("config with %s equals config with %s", name, other)
+		}
+		seen[c] = name
+	}
+}
+
+func TestFinder_FindUsed_cacheWithRoleFilter(t *testing.T) {
+	requireBrowser(t)
+	srv := serveFixture(t, "site", 0)
+	dir := t.TempDir()
+
+	filtered, err := New(srv.URL, 0, WithCache(dir), WithRoleFilter([]string{"link"}))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	if _, err := filtered.FindUsed(context.Background()); err != nil {
+		t.Fatalf("FindUsed() with role filter failed: %v", err)
+	}
+
+	f, err := New(srv.URL, 0, WithCache(dir))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	used, err := f.FindUsed(context.Background())
+	if err != nil {
+		t.Fatalf("FindUsed() failed: %v", err)
+	}
+	if !maps.Equal(used, fixtureUsed) {
+		t.Errorf("FindUsed() after a crawl with role filter = %v, want %v", used, fixtureUsed)
+	}
+}
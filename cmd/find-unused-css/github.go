@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bounoable/siteperf"
+)
+
+// printAnnotations prints a GitHub Actions warning annotation for every unused
+// class, pointing to the first rule of each CSS file that references it.
+// Classes whose rules cannot be located are annotated on the file without a
+// line number.
+func printAnnotations(unused []string) error {
+	isUnused := make(map[string]bool, len(unused))
+	for _, class := range unused {
+		isUnused[class] = true
+	}
+
+	for _, path := range strings.Split(*cssFilePathRaw, ",") {
+		css, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		defined, err := siteperf.ExtractClasses(string(css))
+		if err != nil {
+			return fmt.Errorf("extract classes from %q: %w", path, err)
+		}
+
+		details, err := siteperf.ExtractClassesDetailed(string(css))
+		if err != nil {
+			return fmt.Errorf("extract classes from %q: %w", path, err)
+		}
+		lines := make(map[string]int, len(details))
+		for _, d := range details {
+			if len(d.References) > 0 {
+				lines[d.Name] = d.References[0].Line
+			}
+		}
+
+		for _, class := range defined {
+			if !isUnused[class] {
+				continue
+			}
+			props := "file=" + escapeAnnotationProperty(path)
+			if line := lines[class]; line > 0 {
+				props += fmt.Sprintf(",line=%d", line)
+			}
+			fmt.Printf("::warning %s::%s\n", props, escapeAnnotationData("Unused class ."+class))
+		}
+	}
+
+	return nil
+}
+
+// escapeAnnotationData escapes the message of a workflow command.
+func escapeAnnotationData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeAnnotationProperty escapes a property value of a workflow command.
+func escapeAnnotationProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -1,13 +1,17 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/bounoable/siteperf"
@@ -15,74 +19,446 @@ import (
 )
 
 var (
-	rootURLRaw     = flag.String("url", "https://google.com", "Root URL to crawl")
-	cssFilePathRaw = flag.String("css", "style.css", "Path to CSS file")
+	rootURLRaw     = flag.String("url", "https://google.com", "Root URL to crawl (comma-separated to audit multiple websites)")
+	cssFilePathRaw = flag.String("css", "style.css", "Path to CSS file (comma-separated for multiple files)")
 	limit          = flag.Int("limit", 0, "Limit the number of pages to visit")
 	out            = flag.String("out", "", "Path to output file")
+	format         = flag.String("format", "text", "Output format (text, json, json-report, github, prometheus, matrix, or a report format like csv)")
+	cacheDir       = flag.String("cache", "", "Directory to cache page results in")
+	outputDir      = flag.String("output-dir", "", "Directory to write separate report files to")
+	groupByPrefix  = flag.Bool("group-by-prefix", false, "Group unused classes by their prefix (split on \"-\")")
+	classManifest  = flag.String("class-manifest", "", "Path to a JSON manifest that maps source class names to emitted class names")
+	baseline       = flag.String("baseline", "", "Path to the audit of the previous crawl to compare against (updated after each successful run)")
+	limitOutput    = flag.Int("limit-output", 0, "Limit the number of reported unused classes (0 reports all)")
+	offset         = flag.Int("offset", 0, "Skip this many unused classes before reporting them")
+	compact        = flag.Bool("compact", false, "Write JSON without indentation")
+	ignoreFile     = flag.String("ignore-file", "", "Path to a file of classes and glob patterns that are never reported as unused (default .siteperfignore, if it exists)")
+	validateCSS    = flag.Bool("validate-css", false, "Validate the CSS files and report their classes without crawling")
+	yes            = flag.Bool("yes", false, "Write output files without confirmation, even if the audit is likely wrong")
+	failOnRegress  = flag.Float64("fail-on-regression", -1, "Fail if the number of unused classes grew by more than this percentage compared to -baseline (negative disables)")
 )
 
 func main() {
 	flag.Parse()
 
+	if err := run(); err != nil {
+		exitWithError(err)
+	}
+}
+
+func run() error {
 	defer plog.Debug()()
 
-	if !strings.HasPrefix(*rootURLRaw, "https://") {
-		*rootURLRaw = strings.TrimPrefix(*rootURLRaw, "https://")
-		*rootURLRaw = strings.TrimPrefix(*rootURLRaw, "http://")
-		*rootURLRaw = "https://" + *rootURLRaw
+	if _, ok := siteperf.LookupReportEncoder(*format); !ok && *format != "text" && *format != "json" && *format != "json-report" && *format != "github" && *format != "prometheus" && *format != "matrix" {
+		return fmt.Errorf("unknown output format %q (available: %s)", *format, strings.Join(siteperf.ReportFormats(), ", "))
+	}
+
+	if *validateCSS {
+		return runValidate(strings.Split(*cssFilePathRaw, ","))
+	}
+
+	roots := strings.Split(*rootURLRaw, ",")
+	for i, root := range roots {
+		roots[i] = normalizeRootURL(root)
+	}
+	*rootURLRaw = roots[0]
+
+	var opts []siteperf.Option
+	if *cacheDir != "" {
+		opts = append(opts, siteperf.WithCache(*cacheDir))
+	}
+
+	if *classManifest != "" {
+		b, err := os.ReadFile(*classManifest)
+		if err != nil {
+			return fmt.Errorf("read class manifest: %w", err)
+		}
+		var manifest map[string]string
+		if err := json.Unmarshal(b, &manifest); err != nil {
+			return fmt.Errorf("decode class manifest %q: %w", *classManifest, err)
+		}
+		opts = append(opts, siteperf.WithClassManifest(manifest))
 	}
 
-	f, err := siteperf.New(*rootURLRaw, *limit)
+	ignored, err := loadIgnoreFile()
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("load ignore file: %w", err)
+	}
+	if len(ignored) > 0 {
+		opts = append(opts, siteperf.WithIgnoreClasses(ignored...))
+	}
+
+	if len(roots) > 1 {
+		return runMulti(roots, append(opts, siteperf.WithPageLimit(*limit)))
 	}
 
-	classes, err := siteperf.ExtractClassesFromFile(*cssFilePathRaw)
+	f, err := siteperf.New(*rootURLRaw, *limit, opts...)
 	if err != nil {
-		panic(fmt.Errorf("extract classes from %q: %w", *cssFilePathRaw, err))
+		return fmt.Errorf("invalid root URL %q: %w", *rootURLRaw, err)
 	}
 
+	cssFiles := strings.Split(*cssFilePathRaw, ",")
+
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
 
-	unused, err := f.FindUnused(ctx, classes)
+	if *format == "matrix" {
+		return writeMatrix(ctx, f)
+	}
+
+	if *outputDir != "" {
+		audit, err := f.AuditFiles(ctx, cssFiles...)
+		if err != nil {
+			return fmt.Errorf("audit: %w", err)
+		}
+		printDiagnostics(audit)
+		if err := confirmWrite(audit); err != nil {
+			return err
+		}
+		if err := writeReports(*outputDir, audit); err != nil {
+			return fmt.Errorf("write reports: %w", err)
+		}
+		if *format == "text" {
+			fmt.Println("Wrote reports to", *outputDir)
+		}
+		return nil
+	}
+
+	fileClasses, err := siteperf.ExtractClassesFromFiles(ctx, cssFiles, 0)
 	if err != nil {
-		panic(err)
+		return err
+	}
+
+	var classes []string
+	for _, path := range cssFiles {
+		classes = append(classes, fileClasses[path]...)
+	}
+	slices.Sort(classes)
+	classes = slices.Compact(classes)
+
+	if *failOnRegress >= 0 && *baseline == "" {
+		return fmt.Errorf("-fail-on-regression requires -baseline")
+	}
+
+	var prev *siteperf.Audit
+	if *baseline != "" {
+		if prev, err = loadBaseline(*baseline); err != nil {
+			return fmt.Errorf("load baseline: %w", err)
+		}
+	}
+
+	audit, err := f.Audit(ctx, classes)
+	if err != nil {
+		return fmt.Errorf("find unused classes: %w", err)
+	}
+
+	printDiagnostics(audit)
+	if err := confirmWrite(audit); err != nil {
+		return err
+	}
+
+	if err := printUnused(audit); err != nil {
+		return err
+	}
+
+	if *baseline == "" {
+		return nil
+	}
+
+	// A regressed audit does not replace the baseline, so that a broken crawl
+	// keeps failing until it is fixed.
+	if prev != nil && *failOnRegress >= 0 {
+		if delta := siteperf.CompareCrawls(*prev, audit); delta.Regressed(*failOnRegress) {
+			return fmt.Errorf("unused classes grew by %.1f%% (%d -> %d), exceeding the threshold of %.1f%%", delta.PercentChange, delta.PreviousUnused, delta.CurrentUnused, *failOnRegress)
+		}
+	}
+
+	if err := writeJSON(*baseline, audit); err != nil {
+		return fmt.Errorf("update baseline: %w", err)
+	}
+
+	return nil
+}
+
+// normalizeRootURL returns the root URL with an https:// scheme.
+func normalizeRootURL(root string) string {
+	root = strings.TrimSpace(root)
+	if strings.HasPrefix(root, "https://") {
+		return root
+	}
+	root = strings.TrimPrefix(root, "http://")
+	return "https://" + root
+}
+
+// printUnused writes the unused classes of the audit to the output file, if
+// configured, or prints them to stdout. In text format, the unused classes are
+// printed as part of a report of the audit, unless they are grouped by prefix.
+// In json format, they are printed as a JSON array, and in json-report format
+// along with the coverage (see jsonReport). In github format, the unused
+// classes are printed as GitHub Actions annotations. In any other format than
+// text, json, and json-report, the whole audit is encoded using the report
+// encoder of that format. Only the page of unused classes selected by -offset
+// and -limit-output is reported.
+func printUnused(audit siteperf.Audit) error {
+	audit.Unused = audit.UnusedPage(*offset, *limitOutput)
+
+	if *format == "github" {
+		return printAnnotations(audit.Unused)
+	}
+
+	if *format != "text" && *format != "json" && *format != "json-report" {
+		return encodeReport(audit)
 	}
 
 	if *out != "" {
-		if err := writeOutfile(unused); err != nil {
-			panic(err)
+		if err := writeOutfile(audit.Unused); err != nil {
+			return fmt.Errorf("write output file: %w", err)
 		}
-		fmt.Println("Wrote unused classes to", *out)
-		return
+		if *format == "text" {
+			fmt.Println("Wrote unused classes to", *out)
+			printCoverage(audit.Coverage)
+		}
+		return nil
+	}
+
+	if *format == "text" && !*groupByPrefix {
+		return siteperf.WriteReport(os.Stdout, audit)
 	}
 
-	out, err := json.MarshalIndent(unused, "", "  ")
+	var result any = audit.Unused
+	if *groupByPrefix {
+		result = siteperf.GroupByPrefix(audit.Unused)
+	}
+	if *format == "json-report" {
+		result = jsonReport{Unused: result, Coverage: audit.Coverage}
+	}
+
+	out, err := marshalJSON(result)
 	if err != nil {
-		panic(err)
+		return err
 	}
 
-	fmt.Println("Unused classes:")
+	if *format == "text" {
+		fmt.Println("Unused classes:")
+	}
 	fmt.Println(string(out))
+
+	if *format == "text" {
+		printCoverage(audit.Coverage)
+	}
+
+	return nil
 }
 
-func writeOutfile(unused []string) error {
-	path, err := filepath.Abs(*out)
+// jsonReport is the output of the json-report format: the unused classes, or
+// their groups if they are grouped by prefix, and the coverage of the audit.
+type jsonReport struct {
+	Unused   any                    `json:"unused"`
+	Coverage siteperf.CoverageStats `json:"coverage"`
+}
+
+// encodeReport encodes the audit using the report encoder of the output format,
+// or as Prometheus metrics in prometheus format, and writes it to the output
+// file, if configured, or to stdout.
+func encodeReport(audit siteperf.Audit) error {
+	encode := func(w io.Writer) error {
+		if *format == "prometheus" {
+			return siteperf.WritePrometheus(w, audit, *rootURLRaw)
+		}
+		return siteperf.EncodeReport(w, *format, audit)
+	}
+
+	if *out == "" {
+		return encode(os.Stdout)
+	}
+
+	return writeFileAtomic(*out, encode)
+}
+
+// printDiagnostics prints the diagnostics of the audit to stderr, or as
+// warning annotations in github format. They are not printed if they are part
+// of the text report on stdout.
+func printDiagnostics(audit siteperf.Audit) {
+	if *format == "text" && *out == "" && *outputDir == "" && !*groupByPrefix {
+		return
+	}
+	for _, d := range audit.Diagnostics {
+		if *format == "github" {
+			fmt.Printf("::warning::%s\n", escapeAnnotationData(d.Message))
+			continue
+		}
+		fmt.Fprintln(os.Stderr, "Warning:", d.Message)
+	}
+}
+
+// confirmWrite asks for confirmation before output files are written or the
+// baseline is updated based on an audit that is likely wrong (see
+// [siteperf.Audit.Diagnostics]), so that a misconfigured run does not replace
+// good results. If stdin is not a terminal, the files are not written unless
+// -yes is set.
+func confirmWrite(audit siteperf.Audit) error {
+	if len(audit.Diagnostics) == 0 || *yes || (*out == "" && *outputDir == "" && *baseline == "") {
+		return nil
+	}
+
+	errRefused := errors.New("refusing to write output files because the audit is likely wrong (pass -yes to write them anyway)")
+
+	if stat, err := os.Stdin.Stat(); err != nil || stat.Mode()&os.ModeCharDevice == 0 {
+		return errRefused
+	}
+
+	fmt.Fprint(os.Stderr, "The audit is likely wrong. Write output files anyway? [y/N] ")
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+		return errRefused
+	}
+
+	return nil
+}
+
+func printCoverage(c siteperf.CoverageStats) {
+	fmt.Printf("Coverage: %.1f%% (%d of %d defined classes used, %d unused)\n", c.Percent, c.Used, c.Defined, c.Unused)
+}
+
+// defaultIgnoreFile is the ignore file that is used if -ignore-file is not set.
+const defaultIgnoreFile = ".siteperfignore"
+
+// loadIgnoreFile returns the classes and patterns of the ignore file. If
+// -ignore-file is not set, the default ignore file in the working directory
+// is read, if it exists.
+func loadIgnoreFile() ([]string, error) {
+	path := *ignoreFile
+	if path == "" {
+		path = defaultIgnoreFile
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) && *ignoreFile == "" {
+		return nil, nil
+	}
 	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return siteperf.ParseIgnoreFile(f)
+}
+
+// loadBaseline returns the audit stored at the given path, or nil if no audit
+// is stored yet.
+func loadBaseline(path string) (*siteperf.Audit, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var audit siteperf.Audit
+	if err := json.Unmarshal(b, &audit); err != nil {
+		return nil, fmt.Errorf("decode %q: %w", path, err)
+	}
+
+	return &audit, nil
+}
+
+// exitWithError reports err and exits with a non-zero exit code. If the output
+// format is "json" or "json-report", the error is written to stdout as {"error":"..."} so that
+// downstream tools can parse it. If the output format is "github", it is
+// written as an error annotation. Otherwise, it is written to stderr.
+func exitWithError(err error) {
+	if *format == "github" {
+		fmt.Printf("::error::%s\n", escapeAnnotationData(err.Error()))
+		os.Exit(1)
+	}
+	if *format == "json" || *format == "json-report" {
+		out, _ := json.Marshal(struct {
+			Error string `json:"error"`
+		}{Error: err.Error()})
+		fmt.Println(string(out))
+	} else {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+	}
+	os.Exit(1)
+}
+
+func writeOutfile(unused []string) error {
+	return writeClassList(*out, unused)
+}
+
+// writeReports writes the reports of the audit to separate files within the
+// given directory: unused.txt, undefined.txt, duplicates.json,
+// conflicts.json, usage.json, and coverage.json.
+func writeReports(dir string, audit siteperf.Audit) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	if err := writeClassList(filepath.Join(dir, "unused.txt"), audit.Unused); err != nil {
+		return err
+	}
+
+	if err := writeClassList(filepath.Join(dir, "undefined.txt"), audit.Undefined); err != nil {
+		return err
+	}
+
+	duplicates := audit.Duplicates
+	if duplicates == nil {
+		duplicates = make(map[string][]string)
+	}
+	if err := writeJSON(filepath.Join(dir, "duplicates.json"), duplicates); err != nil {
+		return err
+	}
+
+	conflicts := audit.Conflicts
+	if conflicts == nil {
+		conflicts = make(map[string][]siteperf.DeclarationConflict)
+	}
+	if err := writeJSON(filepath.Join(dir, "conflicts.json"), conflicts); err != nil {
+		return err
+	}
+
+	if err := writeJSON(filepath.Join(dir, "usage.json"), audit.Usage); err != nil {
 		return err
 	}
-	f, err := os.Create(path)
+
+	return writeJSON(filepath.Join(dir, "coverage.json"), audit.Coverage)
+}
+
+func writeJSON(path string, v any) error {
+	b, err := marshalJSON(v)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
+	return writeFileAtomic(path, func(w io.Writer) error {
+		_, err := w.Write(append(b, '\n'))
+		return err
+	})
+}
 
-	for _, name := range unused {
-		if _, err := f.WriteString("." + name + "\n"); err != nil {
-			return err
-		}
+// marshalJSON encodes v as indented JSON, or as compact JSON if -compact is
+// set.
+func marshalJSON(v any) ([]byte, error) {
+	if *compact {
+		return json.Marshal(v)
 	}
+	return json.MarshalIndent(v, "", "  ")
+}
 
-	return f.Close()
+func writeClassList(path string, classes []string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, func(w io.Writer) error {
+		bw := bufio.NewWriter(w)
+		for _, name := range classes {
+			if _, err := bw.WriteString("." + name + "\n"); err != nil {
+				return err
+			}
+		}
+		return bw.Flush()
+	})
 }
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/bounoable/siteperf"
+)
+
+// writeMatrix crawls the website and writes its page×class usage matrix (see
+// [siteperf.Finder.UsageMatrix]) as CSV to the output file, if configured, or
+// to stdout. The first row contains the classes, and every other row contains
+// a page followed by the usage counts of the classes on that page.
+func writeMatrix(ctx context.Context, f *siteperf.Finder) error {
+	pages, classes, counts, err := f.UsageMatrix(ctx)
+	if err != nil {
+		return fmt.Errorf("build usage matrix: %w", err)
+	}
+
+	if *out == "" {
+		return encodeMatrix(os.Stdout, pages, classes, counts)
+	}
+
+	return writeFileAtomic(*out, func(w io.Writer) error {
+		return encodeMatrix(w, pages, classes, counts)
+	})
+}
+
+func encodeMatrix(w io.Writer, pages, classes []string, counts [][]int) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(append([]string{"page"}, classes...)); err != nil {
+		return err
+	}
+
+	row := make([]string, len(classes)+1)
+	for i, page := range pages {
+		row[0] = page
+		for j, count := range counts[i] {
+			row[j+1] = strconv.Itoa(count)
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/bounoable/siteperf"
+)
+
+// runMulti audits the CSS files against the websites of multiple root URLs
+// (see [siteperf.AuditMulti]). In text format, the report of the union of all
+// websites is printed, followed by the coverage of every website. In json
+// format, the whole [siteperf.MultiAudit] is printed. The page limit applies
+// to every website. Other output options are not supported for multiple
+// websites.
+func runMulti(roots []string, opts []siteperf.Option) error {
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("output format %q is not supported for multiple URLs", *format)
+	}
+	if *out != "" || *outputDir != "" || *baseline != "" {
+		return fmt.Errorf("-out, -output-dir, and -baseline are not supported for multiple URLs")
+	}
+
+	var css strings.Builder
+	for _, path := range strings.Split(*cssFilePathRaw, ",") {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read CSS file: %w", err)
+		}
+		css.Write(b)
+		css.WriteByte('\n')
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	audit, err := siteperf.AuditMulti(ctx, roots, css.String(), opts...)
+	if err != nil {
+		return err
+	}
+
+	if *format == "json" {
+		b, err := marshalJSON(audit)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+
+	audit.Audit.Unused = audit.Audit.UnusedPage(*offset, *limitOutput)
+	if err := siteperf.WriteReport(os.Stdout, audit.Audit); err != nil {
+		return err
+	}
+
+	fmt.Println("\nCoverage per website:")
+	for _, root := range roots {
+		fmt.Print("  ", root, ": ")
+		printCoverage(audit.Roots[root].Coverage)
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bounoable/siteperf"
+)
+
+// runValidate validates the CSS files without crawling (see
+// [siteperf.ValidateCSS]) and prints a report of every file, as JSON in json
+// and json-report format. It fails if any of the files has warnings.
+func runValidate(paths []string) error {
+	reports := make([]siteperf.CSSReport, 0, len(paths))
+	valid := true
+	for _, path := range paths {
+		report, err := siteperf.ValidateCSS(path)
+		if err != nil {
+			return fmt.Errorf("validate %q: %w", path, err)
+		}
+		reports = append(reports, report)
+		valid = valid && report.Valid()
+	}
+
+	if *format == "json" || *format == "json-report" {
+		b, err := marshalJSON(reports)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+	} else {
+		for i, report := range reports {
+			if i > 0 {
+				fmt.Println()
+			}
+			printCSSReport(report)
+		}
+	}
+
+	if !valid {
+		return errors.New("CSS validation failed")
+	}
+
+	return nil
+}
+
+func printCSSReport(r siteperf.CSSReport) {
+	fmt.Println(r.Path)
+	fmt.Printf("  Rules:   %d\n", r.Rules)
+	fmt.Printf("  Classes: %d\n", r.Classes)
+	if len(r.Sample) > 0 {
+		fmt.Printf("  Sample:  %s\n", strings.Join(r.Sample, ", "))
+	}
+	if len(r.InvalidTokens) > 0 {
+		fmt.Printf("  Invalid class tokens (not audited): %s\n", strings.Join(r.InvalidTokens, ", "))
+	}
+	for _, warning := range r.Warnings {
+		if *format == "github" {
+			fmt.Printf("::warning file=%s::%s\n", escapeAnnotationProperty(r.Path), escapeAnnotationData(warning))
+			continue
+		}
+		fmt.Printf("  Warning: %s\n", warning)
+	}
+}
@@ -0,0 +1,71 @@
+package siteperf
+
+import "slices"
+
+// CrawlDelta is the change in unused classes between two consecutive audits
+// of the same website.
+type CrawlDelta struct {
+	// AddedUnused contains the classes that are unused in the current audit,
+	// but were not unused in the previous one, sorted by name.
+	AddedUnused []string `json:"addedUnused"`
+
+	// RemovedUnused contains the classes that were unused in the previous
+	// audit, but are not unused in the current one, sorted by name.
+	RemovedUnused []string `json:"removedUnused"`
+
+	// PreviousUnused and CurrentUnused are the number of unused classes of
+	// the previous and the current audit.
+	PreviousUnused int `json:"previousUnused"`
+	CurrentUnused  int `json:"currentUnused"`
+
+	// PercentChange is the relative change of the number of unused classes in
+	// percent. It is positive if the number of unused classes grew. If the
+	// previous audit had no unused classes, PercentChange is 100 if the
+	// current audit has unused classes, and 0 otherwise.
+	PercentChange float64 `json:"percentChange"`
+}
+
+// CompareCrawls compares the unused classes of two consecutive audits. A
+// sudden growth of unused classes often indicates a bad deploy or a broken
+// crawl rather than actual dead CSS (see [CrawlDelta.Regressed]).
+func CompareCrawls(prev, curr Audit) CrawlDelta {
+	delta := CrawlDelta{
+		AddedUnused:    missingFrom(curr.Unused, prev.Unused),
+		RemovedUnused:  missingFrom(prev.Unused, curr.Unused),
+		PreviousUnused: len(unique(prev.Unused)),
+		CurrentUnused:  len(unique(curr.Unused)),
+	}
+
+	switch {
+	case delta.PreviousUnused > 0:
+		delta.PercentChange = 100 * float64(delta.CurrentUnused-delta.PreviousUnused) / float64(delta.PreviousUnused)
+	case delta.CurrentUnused > 0:
+		delta.PercentChange = 100
+	}
+
+	return delta
+}
+
+// Regressed reports whether the number of unused classes grew by more than
+// the given threshold in percent.
+func (d CrawlDelta) Regressed(threshold float64) bool {
+	return d.CurrentUnused > d.PreviousUnused && d.PercentChange > threshold
+}
+
+// missingFrom returns the sorted classes of a that are not in b.
+func missingFrom(a, b []string) []string {
+	in := make(map[string]bool, len(b))
+	for _, class := range b {
+		in[class] = true
+	}
+
+	out := make([]string, 0)
+	for _, class := range unique(a) {
+		if !in[class] {
+			out = append(out, class)
+		}
+	}
+	slices.Sort(out)
+
+	return out
+}
@@ -0,0 +1,85 @@
+package siteperf
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// ComponentUsage crawls the website of the Finder and counts the classes that
+// are used within the shadow trees of web components, grouped by the tag name
+// of the component that hosts them, e.g. {"my-button": {"label": 4}}. This
+// allows to audit the classes of a design system per component (see
+// [Finder.FindUnusedFromUsed]), whose styles and classes are invisible to the
+// regular extraction of the Finder.
+//
+// A class is counted once for every element within a shadow tree that has it,
+// and is attributed to the innermost component: the classes within the shadow
+// tree of a component that is itself rendered within the shadow tree of
+// another component are attributed to the inner component. The class attribute
+// of a component's host element belongs to the tree the host is rendered in.
+// Both open and closed shadow trees are traversed, because the document is
+// read through the DevTools protocol instead of the page's scripts. Classes
+// outside of any shadow tree, the built-in shadow trees of elements like
+// <input>, and the contents of <template> elements and frames are not counted.
+func (f *Finder) ComponentUsage(ctx context.Context) (map[string]map[string]int, error) {
+	var mux sync.Mutex
+	usage := make(map[string]map[string]int)
+
+	_, err := f.crawl(ctx, func(ctx context.Context, page *rod.Page, result *pageResult) error {
+		depth := -1
+		doc, err := proto.DOMGetDocument{Depth: &depth, Pierce: true}.Call(page)
+		if err != nil {
+			return fmt.Errorf("get document: %w", err)
+		}
+
+		mux.Lock()
+		defer mux.Unlock()
+		f.countComponentClasses(usage, doc.Root, "")
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("find component usage: %w", err)
+	}
+
+	return usage, nil
+}
+
+// countComponentClasses adds the classes of the node and its descendants to
+// the usage of the given component. An empty component means that the node is
+// not within a shadow tree. The shadow trees of the node are counted towards
+// the node itself.
+func (f *Finder) countComponentClasses(usage map[string]map[string]int, node *proto.DOMNode, component string) {
+	if node == nil {
+		return
+	}
+
+	if component != "" {
+		for i := 0; i+1 < len(node.Attributes); i += 2 {
+			if node.Attributes[i] != "class" {
+				continue
+			}
+			for _, class := range f.classTokens(node.Attributes[i+1]) {
+				if usage[component] == nil {
+					usage[component] = make(map[string]int)
+				}
+				usage[component][class]++
+			}
+		}
+	}
+
+	for _, root := range node.ShadowRoots {
+		if root.ShadowRootType == proto.DOMShadowRootTypeUserAgent {
+			continue
+		}
+		f.countComponentClasses(usage, root, node.LocalName)
+	}
+
+	for _, child := range node.Children {
+		f.countComponentClasses(usage, child, component)
+	}
+}
@@ -0,0 +1,98 @@
+package siteperf
+
+import (
+	"context"
+	"maps"
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// fixtureComponentUsage is the component usage of the "components" fixture,
+// whose components are plain custom elements.
+var fixtureComponentUsage = map[string]map[string]int{
+	"my-card": {
+		"card":         2,
+		"card__title":  2,
+		"card__action": 2,
+	},
+	"my-button": {
+		"button":          3,
+		"button--primary": 3,
+		"button__label":   3,
+	},
+}
+
+func TestFinder_countComponentClasses(t *testing.T) {
+	element := func(tag, class string, children ...*proto.DOMNode) *proto.DOMNode {
+		node := &proto.DOMNode{LocalName: tag, Children: children}
+		if class != "" {
+			node.Attributes = []string{"id", "x", "class", class}
+		}
+		return node
+	}
+	shadowRoot := func(typ proto.DOMShadowRootType, children ...*proto.DOMNode) *proto.DOMNode {
+		return &proto.DOMNode{ShadowRootType: typ, Children: children}
+	}
+	button := func(class string) *proto.DOMNode {
+		host := element("my-button", class)
+		host.ShadowRoots = []*proto.DOMNode{shadowRoot(proto.DOMShadowRootTypeClosed,
+			element("button", "button button--primary", element("span", "button__label", element("slot", ""))),
+		)}
+		return host
+	}
+	card := func(class, title string) *proto.DOMNode {
+		host := element("my-card", class, element("span", title))
+		host.ShadowRoots = []*proto.DOMNode{shadowRoot(proto.DOMShadowRootTypeOpen,
+			element("div", "card",
+				element("h2", "card__title", element("slot", "")),
+				button("card__action"),
+			),
+		)}
+		return host
+	}
+	input := element("input", "field")
+	input.ShadowRoots = []*proto.DOMNode{shadowRoot(proto.DOMShadowRootTypeUserAgent, element("div", "user-agent"))}
+
+	doc := element("#document", "",
+		element("html", "",
+			element("body", "page",
+				card("theme-dark", "slotted-title"),
+				card("", "slotted-title"),
+				button(""),
+				input,
+			),
+		),
+	)
+
+	f, err := New("https://example.com", 0)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	usage := make(map[string]map[string]int)
+	f.countComponentClasses(usage, doc, "")
+
+	if !maps.EqualFunc(usage, fixtureComponentUsage, maps.Equal) {
+		t.Errorf("countComponentClasses() = %v, want %v", usage, fixtureComponentUsage)
+	}
+}
+
+func TestFinder_ComponentUsage_customElements(t *testing.T) {
+	requireBrowser(t)
+	srv := serveFixture(t, "components", 0)
+
+	f, err := New(srv.URL, 0)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	usage, err := f.ComponentUsage(context.Background())
+	if err != nil {
+		t.Fatalf("ComponentUsage() failed: %v", err)
+	}
+
+	if !maps.EqualFunc(usage, fixtureComponentUsage, maps.Equal) {
+		t.Errorf("ComponentUsage() = %v, want %v", usage, fixtureComponentUsage)
+	}
+}
@@ -0,0 +1,148 @@
+package siteperf
+
+import (
+	"slices"
+	"strings"
+)
+
+// DeclarationConflict is a property that is set to different values by rules
+// with the same selector in different CSS files. Which value applies depends
+// on the order in which the files are loaded, which is a common source of
+// styling bugs.
+type DeclarationConflict struct {
+	// Selector is the selector of the conflicting rules.
+	Selector string `json:"selector"`
+
+	// Conditions contains the preludes of the conditional group rules (e.g.
+	// "@media (min-width: 768px)") that enclose the conflicting rules.
+	Conditions []string `json:"conditions,omitempty"`
+
+	// Property is the conflicting property.
+	Property string `json:"property"`
+
+	// Declarations contains the declarations of the property, one per file,
+	// in the order of the files.
+	Declarations []ConflictingDeclaration `json:"declarations"`
+}
+
+// ConflictingDeclaration is a declaration of a [DeclarationConflict].
+type ConflictingDeclaration struct {
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Value     string `json:"value"`
+	Important bool   `json:"important,omitempty"`
+}
+
+// cssSource is the CSS of a file.
+type cssSource struct {
+	path string
+	css  string
+}
+
+// FindDeclarationConflicts parses the given CSS files, which map file paths to
+// their CSS, and returns the conflicting declarations of the classes that are
+// defined in more than one of them. A declaration conflicts if a rule with the
+// same selector and within the same conditional group rules sets the same
+// property to a different value in another file. Rules with different
+// selectors, like ".btn" and ".btn:hover", never conflict. Within a file, the
+// last declaration of a property wins, like in the cascade.
+//
+// The conflicts are mapped to every class of their selector and are sorted by
+// selector and property.
+func FindDeclarationConflicts(files map[string]string) map[string][]DeclarationConflict {
+	sources := make([]cssSource, 0, len(files))
+	for path, css := range files {
+		sources = append(sources, cssSource{path: path, css: css})
+	}
+	slices.SortFunc(sources, func(a, b cssSource) int {
+		return strings.Compare(a.path, b.path)
+	})
+	return declarationConflicts(sources)
+}
+
+func declarationConflicts(sources []cssSource) map[string][]DeclarationConflict {
+	type ruleKey struct {
+		selector   string
+		conditions string
+		property   string
+	}
+
+	var (
+		keys       []ruleKey
+		conditions = make(map[ruleKey][]string)
+		decls      = make(map[ruleKey][]ConflictingDeclaration)
+	)
+	for _, src := range sources {
+		for _, rule := range parseStylesheet(src.css).rules {
+			for _, selector := range rule.selectors {
+				selector = strings.Join(strings.Fields(selector), " ")
+				for _, decl := range rule.declarations {
+					key := ruleKey{selector, strings.Join(rule.conditions, "\x00"), decl.property}
+					d := ConflictingDeclaration{
+						File:      src.path,
+						Line:      rule.line,
+						Value:     strings.Join(strings.Fields(decl.value), " "),
+						Important: decl.important,
+					}
+
+					existing := decls[key]
+					if len(existing) == 0 {
+						keys = append(keys, key)
+						conditions[key] = rule.conditions
+					}
+					if n := len(existing); n > 0 && existing[n-1].File == src.path {
+						existing[n-1] = d
+						continue
+					}
+					decls[key] = append(existing, d)
+				}
+			}
+		}
+	}
+
+	out := make(map[string][]DeclarationConflict)
+	for _, key := range keys {
+		declarations := decls[key]
+		if !conflicting(declarations) {
+			continue
+		}
+
+		conflict := DeclarationConflict{
+			Selector:     key.selector,
+			Conditions:   conditions[key],
+			Property:     key.property,
+			Declarations: declarations,
+		}
+		var classes []string
+		for _, sel := range parseSelectorList(key.selector) {
+			for _, ref := range selectorClassRefs(sel, false, false) {
+				classes = append(classes, ref.name)
+			}
+		}
+		for _, class := range unique(classes) {
+			out[class] = append(out[class], conflict)
+		}
+	}
+
+	for _, conflicts := range out {
+		slices.SortFunc(conflicts, func(a, b DeclarationConflict) int {
+			if c := strings.Compare(a.Selector, b.Selector); c != 0 {
+				return c
+			}
+			return strings.Compare(a.Property, b.Property)
+		})
+	}
+
+	return out
+}
+
+// conflicting reports whether the declarations of different files set their
+// property to different values.
+func conflicting(decls []ConflictingDeclaration) bool {
+	for _, d := range decls[1:] {
+		if d.Value != decls[0].Value || d.Important != decls[0].Important {
+			return true
+		}
+	}
+	return false
+}
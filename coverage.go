@@ -0,0 +1,51 @@
+package siteperf
+
+// CoverageStats summarizes how many of the defined classes are used.
+type CoverageStats struct {
+	// Defined is the number of distinct defined classes.
+	Defined int `json:"defined"`
+
+	// Used and Unused are the number of defined classes that are used and
+	// unused on the crawled pages.
+	Used   int `json:"used"`
+	Unused int `json:"unused"`
+
+	// Percent is the percentage of defined classes that are used (see
+	// [Coverage]).
+	Percent float64 `json:"percent"`
+}
+
+// Coverage returns the percentage (0 to 100) of the defined classes that are
+// used according to the given used-class counts, as returned by
+// [Finder.FindUsed]. If no classes are defined, the coverage is 100.
+func Coverage(defined []string, used map[string]int) float64 {
+	return NewCoverageStats(defined, used).Percent
+}
+
+// NewCoverageStats returns the [CoverageStats] of the defined classes
+// according to the given used-class counts.
+func NewCoverageStats(defined []string, used map[string]int) CoverageStats {
+	defined = unique(defined)
+	unused := filter(defined, func(class string) bool { return used[class] <= 0 })
+	return coverageStats(len(defined), len(unused))
+}
+
+// coverage works like [NewCoverageStats], but matches the classes like
+// [Finder.FindUnusedFromUsed].
+func (f *Finder) coverage(defined []string, used map[string]int) CoverageStats {
+	defined = unique(defined)
+	return coverageStats(len(defined), len(f.FindUnusedFromUsed(used, defined)))
+}
+
+func coverageStats(defined, unused int) CoverageStats {
+	stats := CoverageStats{
+		Defined: defined,
+		Used:    defined - unused,
+		Unused:  unused,
+		Percent: 100,
+	}
+	if defined > 0 {
+		stats.Percent = 100 * float64(stats.Used) / float64(defined)
+	}
+	return stats
+}
@@ -0,0 +1,105 @@
+package siteperf
+
+import (
+	"context"
+	"time"
+)
+
+// CrawlResult is the result of a crawl that was started by [Finder.StartCrawl].
+type CrawlResult struct {
+	// Pages contains the URLs of the crawled pages.
+	Pages []string `json:"pages"`
+
+	// Used maps every class found on the crawled pages to the number of
+	// elements it was found on.
+	Used map[string]int `json:"used"`
+}
+
+// CrawlMeta describes a crawl whose results are stored alongside an [Audit],
+// e.g. by WriteSQLite.
+type CrawlMeta struct {
+	// RootURL is the URL the crawl was started from.
+	RootURL string `json:"rootUrl"`
+
+	// StartedAt and FinishedAt are the times at which the crawl was started
+	// and finished.
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+
+	// Pages contains the URLs of the crawled pages.
+	Pages []string `json:"pages,omitempty"`
+}
+
+// Crawl is a handle to a crawl that runs in the background. It allows to stop
+// the crawl independently of the context it was started with and to wait for
+// its result. A Crawl is safe for concurrent use.
+type Crawl struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	result CrawlResult
+	err    error
+}
+
+// StartCrawl starts crawling the website of the Finder in the background and
+// returns a handle to the running crawl. The crawl is stopped when either the
+// provided context is canceled or [Crawl.Stop] is called. StartCrawl returns an
+// error if the browser cannot be connected to. Multiple crawls may run
+// concurrently, each using its own browser.
+func (f *Finder) StartCrawl(ctx context.Context) (*Crawl, error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	browser, err := f.connect(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	c := &Crawl{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	go func() {
+		defer close(c.done)
+		defer cancel()
+
+		result, err := f.run(ctx, browser)
+		if err != nil {
+			c.err = err
+			return
+		}
+		c.result = result.export()
+	}()
+
+	return c, nil
+}
+
+// Stop stops the crawl. Pages that have already been crawled are still part
+// of the result returned by [Crawl.Wait]. Calling Stop multiple times, or after
+// the crawl has finished, has no effect.
+func (c *Crawl) Stop() {
+	c.cancel()
+}
+
+// Done returns a channel that is closed when the crawl has finished.
+func (c *Crawl) Done() <-chan struct{} {
+	return c.done
+}
+
+// Wait blocks until the crawl has finished and returns its result.
+func (c *Crawl) Wait() (CrawlResult, error) {
+	<-c.done
+	return c.result, c.err
+}
+
+func (r *crawlResult) export() CrawlResult {
+	out := CrawlResult{
+		Pages: make([]string, 0, len(r.pages)),
+	}
+	for _, page := range r.pages {
+		out.Pages = append(out.Pages, page.url)
+	}
+	out.Used = r.usedCounts()
+	return out
+}
@@ -1,9 +1,18 @@
 package siteperf
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"regexp"
+	"runtime"
 	"slices"
+	"strings"
+	"sync"
+	"unicode"
 )
 
 // ExtractClassesFromFile reads the CSS file specified by the given path and
@@ -19,30 +28,206 @@ func ExtractClassesFromFile(path string) ([]string, error) {
 	return ExtractClasses(string(bytes))
 }
 
+// ExtractClassesFromFS works like [ExtractClassesFromFile], but reads the file
+// from the given file system, e.g. an [embed.FS] of embedded stylesheets. The
+// path must be a valid path according to [fs.ValidPath].
+func ExtractClassesFromFS(fsys fs.FS, path string) ([]string, error) {
+	bytes, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+	return ExtractClasses(string(bytes))
+}
+
+// ExtractClassesFromFiles works like [ExtractClassesFromFile], but reads and
+// extracts the given files in parallel using at most concurrency workers. If
+// concurrency is 0 or less, the number of CPUs is used. It returns the classes
+// of each file keyed by its path. If a file cannot be read, or the context is
+// canceled, the remaining files are skipped and the error is returned.
+func ExtractClassesFromFiles(ctx context.Context, paths []string, concurrency int) (map[string][]string, error) {
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var (
+		mux sync.Mutex
+		wg  sync.WaitGroup
+		out = make(map[string][]string, len(paths))
+	)
+
+	queue := make(chan string)
+	for i := 0; i < min(concurrency, len(paths)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range queue {
+				classes, err := ExtractClassesFromFile(path)
+				if err != nil {
+					cancel(fmt.Errorf("extract classes from %q: %w", path, err))
+					continue
+				}
+				mux.Lock()
+				out[path] = classes
+				mux.Unlock()
+			}
+		}()
+	}
+
+	func() {
+		defer close(queue)
+		for _, path := range paths {
+			select {
+			case <-ctx.Done():
+				return
+			case queue <- path:
+			}
+		}
+	}()
+	wg.Wait()
+
+	if err := context.Cause(ctx); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
 // ExtractClasses extracts class names from a provided CSS string. It returns a
 // sorted, unique list of class names without the leading dot, ensuring that
-// each class name is valid according to CSS naming conventions. If any error
-// occurs during the extraction, an error is returned alongside an empty slice.
+// each class name is valid according to CSS naming conventions. Dot-prefixed
+// text within comments and string literals, like content: ".foo", is ignored.
+// If any error occurs during the extraction, an error is returned alongside an
+// empty slice.
 func ExtractClasses(css string) ([]string, error) {
 	var classes []string
-	re := regexp.MustCompile(`\.[a-zA-Z0-9_-]+`)
 
-	matches := re.FindAllStringSubmatch(css, -1)
+	// Comments are removed first, because a quote within a comment, like the
+	// apostrophe of "/*! Bootstrap's grid */", does not start a string.
+	css, _ = blankStrings(stripComments(css))
+
+	matches := classTokenRE.FindAllStringSubmatch(css, -1)
 
 	for _, match := range matches {
-		classes = append(classes, match[0][1:])
+		if class, ok := classFromToken(match[0]); ok {
+			classes = append(classes, class)
+		}
 	}
 
-	classes = filter(classes, func(s string) bool {
-		return isValidClass(s)
-	})
-
 	classes = unique(classes)
 	slices.Sort(classes)
 
 	return classes, nil
 }
 
+// classTokenRE matches class selectors, including class names with escaped
+// characters like ".md\:hidden" or ".w-1\/2" that utility frameworks use for
+// variants and fractions.
+var classTokenRE = regexp.MustCompile(`\.(?:[a-zA-Z0-9_-]|\\[^\n])+`)
+
+// classFromToken returns the class name of a token matched by classTokenRE,
+// with escape sequences resolved. Tokens without escape sequences must be
+// valid class names, which rules out numbers like the ".5" of "0.5rem".
+func classFromToken(token string) (string, bool) {
+	name := token[1:]
+	if !strings.Contains(name, "\\") {
+		return name, isValidClass(name)
+	}
+	class, _ := readIdent(name, 0)
+	return class, class != "" && !strings.ContainsFunc(class, unicode.IsSpace)
+}
+
+const (
+	// readerChunkSize is the size of the chunks that [ExtractClassesReader]
+	// reads.
+	readerChunkSize = 64 * 1024
+
+	// maxReaderCarry is the maximum length of a class token that
+	// [ExtractClassesReader] carries over to the next chunk. Longer tokens
+	// are cut at the chunk boundary.
+	maxReaderCarry = 4 * 1024
+)
+
+// ExtractClassesReader works like [ExtractClasses], but reads the CSS from the
+// given reader in chunks instead of holding it in memory as a whole, which
+// makes it suitable for very large stylesheets. Class names, comments, and
+// string literals that span chunk boundaries are handled, and the memory used
+// does not grow with the size of the CSS, apart from the found classes. The
+// context is checked between chunks.
+func ExtractClassesReader(ctx context.Context, r io.Reader) ([]string, error) {
+	found := make(map[string]bool)
+	buf := make([]byte, readerChunkSize)
+	var (
+		blanker cssBlanker
+		carry   []byte
+	)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		n, err := r.Read(buf)
+		eof := errors.Is(err, io.EOF)
+		if err != nil && !eof {
+			return nil, err
+		}
+
+		// The blanker keeps track of the comment or string the chunk ends
+		// in, so only the blanked chunk is scanned for classes.
+		blanker.blank(buf[:n])
+		chunk := append(carry, buf[:n]...)
+
+		// A class name at the end of the chunk may continue in the next
+		// chunk, so it is carried over, along with its leading dot.
+		end := len(chunk)
+		if !eof {
+			if end = trailingTokenStart(chunk); len(chunk)-end > maxReaderCarry {
+				end = len(chunk)
+			}
+		}
+
+		for _, match := range classTokenRE.FindAll(chunk[:end], -1) {
+			if class, ok := classFromToken(string(match)); ok {
+				found[class] = true
+			}
+		}
+		carry = append(carry[:0], chunk[end:]...)
+
+		if eof {
+			break
+		}
+	}
+
+	classes := make([]string, 0, len(found))
+	for class := range found {
+		classes = append(classes, class)
+	}
+	slices.Sort(classes)
+
+	return classes, nil
+}
+
+// trailingTokenStart returns the offset of the dot that starts the class
+// token at the end of the chunk, or the length of the chunk if it does not end
+// with a (possibly incomplete) class token.
+func trailingTokenStart(chunk []byte) int {
+	i := len(chunk)
+	for i > 0 && (isClassTokenChar(chunk[i-1]) || chunk[i-1] == '\\' || (i > 1 && chunk[i-2] == '\\')) {
+		i--
+	}
+	if i > 0 && chunk[i-1] == '.' {
+		return i - 1
+	}
+	return len(chunk)
+}
+
+func isClassTokenChar(c byte) bool {
+	return c == '-' || c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
 var validClassRE = regexp.MustCompile(`^(?:[a-zA-Z_][a-zA-Z0-9_-]*$)`)
 
 func isValidClass(name string) bool {
@@ -63,3 +248,302 @@ func unique[S ~[]E, E comparable](s S) S {
 	}
 	return unique
 }
+
+// SelectorKind describes the context in which a class is referenced by a CSS
+// selector. It helps to judge how safe it is to remove a class.
+type SelectorKind string
+
+const (
+	// Standalone means that the class is referenced on its own, or only
+	// together with other simple selectors for the same element, like ".btn"
+	// or "button.btn".
+	Standalone = SelectorKind("standalone")
+
+	// Combinator means that the class is referenced within a selector that
+	// combines multiple elements, like ".nav > .item" or ".card .title".
+	Combinator = SelectorKind("combinator")
+
+	// State means that the class is referenced together with a pseudo-class or
+	// pseudo-element, like ".btn:hover" or ".icon::before".
+	State = SelectorKind("state")
+
+	// Negated means that the class is referenced within the argument of a
+	// :not() pseudo-class, like "button:not(.disabled)". Such a reference
+	// selects the elements that do not have the class, so removing the class
+	// from the stylesheet may change which elements are styled, even if no
+	// element has the class.
+	Negated = SelectorKind("negated")
+)
+
+// ClassReference is a single reference to a class by a selector of a
+// stylesheet.
+type ClassReference struct {
+	// Selector is the selector that references the class.
+	Selector string `json:"selector"`
+
+	// Line is the 1-based line number of the rule that contains the
+	// selector.
+	Line int `json:"line"`
+
+	// Kinds describes the context of the reference. A reference can be of
+	// multiple kinds, e.g. ".nav .item:hover" is both a [Combinator] and a
+	// [State] reference of "item". References that are neither are
+	// [Standalone].
+	Kinds []SelectorKind `json:"kinds"`
+
+	// Media contains the media queries of the @media rules that enclose the
+	// reference, from outermost to innermost.
+	Media []string `json:"media,omitempty"`
+}
+
+// NonScreen reports whether the reference only applies to non-screen media,
+// i.e. it is enclosed by an @media rule that only matches media types like
+// "print" or "speech".
+func (ref ClassReference) NonScreen() bool {
+	return slices.ContainsFunc(ref.Media, isNonScreenMedia)
+}
+
+// ClassDetails contains all references to a class within a stylesheet.
+type ClassDetails struct {
+	// Name is the name of the class without the leading dot and with CSS
+	// escape sequences resolved.
+	Name string `json:"name"`
+
+	// References contains the references to the class in the order in which
+	// they appear in the stylesheet.
+	References []ClassReference `json:"references"`
+}
+
+// NonScreenOnly reports whether every reference to the class only applies to
+// non-screen media like "print". Such classes are never applied while a page
+// is rendered on screen, so a crawl cannot find them, even though they are
+// used.
+func (d ClassDetails) NonScreenOnly() bool {
+	if len(d.References) == 0 {
+		return false
+	}
+	for _, ref := range d.References {
+		if !ref.NonScreen() {
+			return false
+		}
+	}
+	return true
+}
+
+// NegatedOnly reports whether every reference to the class is within the
+// argument of a :not() pseudo-class (see [Negated]). Such classes are not
+// required to be found on a page to be in use.
+func (d ClassDetails) NegatedOnly() bool {
+	if len(d.References) == 0 {
+		return false
+	}
+	for _, ref := range d.References {
+		if !slices.Contains(ref.Kinds, Negated) {
+			return false
+		}
+	}
+	return true
+}
+
+// Kinds returns the distinct selector kinds of all references to the class.
+func (d ClassDetails) Kinds() []SelectorKind {
+	var kinds []SelectorKind
+	for _, ref := range d.References {
+		kinds = append(kinds, ref.Kinds...)
+	}
+	kinds = unique(kinds)
+	slices.Sort(kinds)
+	return kinds
+}
+
+// ExtractClassesDetailed parses the provided CSS and returns the details of
+// every class referenced by its selectors, sorted by class name. Unlike
+// [ExtractClasses], which scans the CSS for anything that looks like a class,
+// ExtractClassesDetailed only considers the selectors of style rules and
+// categorizes every reference by its [SelectorKind].
+func ExtractClassesDetailed(css string) ([]ClassDetails, error) {
+	sheet := parseStylesheet(css)
+
+	details := make(map[string]*ClassDetails)
+	for _, rule := range sheet.rules {
+		media := mediaQueries(rule.conditions)
+		for _, raw := range rule.selectors {
+			for _, sel := range parseSelectorList(raw) {
+				for _, ref := range selectorClassRefs(sel, false, false) {
+					d, ok := details[ref.name]
+					if !ok {
+						d = &ClassDetails{Name: ref.name}
+						details[ref.name] = d
+					}
+					d.References = append(d.References, ClassReference{
+						Selector: raw,
+						Line:     rule.line,
+						Kinds:    ref.kinds(),
+						Media:    media,
+					})
+				}
+			}
+		}
+	}
+
+	out := make([]ClassDetails, 0, len(details))
+	for _, d := range details {
+		out = append(out, *d)
+	}
+	slices.SortFunc(out, func(a, b ClassDetails) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return out, nil
+}
+
+// ExtractImportantUsage parses the provided CSS and returns the number of
+// !important declarations within the rules that target each class. A rule
+// counts towards every class referenced by its selectors, so a rule like
+// ".a, .b { color: red !important }" counts once for both "a" and "b".
+// Classes without !important declarations are omitted.
+func ExtractImportantUsage(css string) map[string]int {
+	out := make(map[string]int)
+	for _, rule := range parseStylesheet(css).rules {
+		important := 0
+		for _, decl := range rule.declarations {
+			if decl.important {
+				important++
+			}
+		}
+		if important == 0 {
+			continue
+		}
+
+		var classes []string
+		for _, sel := range parseSelectorList(rule.selector) {
+			for _, ref := range selectorClassRefs(sel, false, false) {
+				classes = append(classes, ref.name)
+			}
+		}
+		for _, class := range unique(classes) {
+			out[class] += important
+		}
+	}
+	return out
+}
+
+// classRef is a reference to a class within a single parsed selector.
+type classRef struct {
+	name       string
+	combinator bool
+	state      bool
+	negated    bool
+}
+
+func (ref classRef) kinds() []SelectorKind {
+	var kinds []SelectorKind
+	if ref.combinator {
+		kinds = append(kinds, Combinator)
+	}
+	if ref.state {
+		kinds = append(kinds, State)
+	}
+	if ref.negated {
+		kinds = append(kinds, Negated)
+	}
+	if len(kinds) == 0 {
+		kinds = append(kinds, Standalone)
+	}
+	return kinds
+}
+
+// selectorClassRefs returns the class references of the given selector,
+// including the references within the arguments of logical pseudo-classes
+// like :is() or :not(). The combinator and state flags of the enclosing
+// selector are inherited by nested references, and references within the
+// arguments of :not() are marked as negated.
+func selectorClassRefs(sel complexSelector, combinator, state bool) []classRef {
+	combinator = combinator || len(sel.compounds) > 1
+
+	var refs []classRef
+	for _, compound := range sel.compounds {
+		compoundState := state
+		for _, ps := range compound.pseudos {
+			if !ps.logical() {
+				compoundState = true
+			}
+		}
+
+		for _, class := range compound.classes {
+			// Escaped whitespace is trimmed like the whitespace around the
+			// tokens of a class attribute (see splitClassList), so that both
+			// sides of the comparison agree on the class name.
+			class = strings.TrimSpace(class)
+			if class == "" {
+				continue
+			}
+			refs = append(refs, classRef{
+				name:       class,
+				combinator: combinator,
+				state:      compoundState,
+			})
+		}
+
+		for _, ps := range compound.pseudos {
+			for _, inner := range ps.selectors {
+				innerRefs := selectorClassRefs(inner, combinator, compoundState)
+				if ps.name == "not" {
+					for i := range innerRefs {
+						innerRefs[i].negated = true
+					}
+				}
+				refs = append(refs, innerRefs...)
+			}
+		}
+	}
+	return refs
+}
+
+// mediaQueries returns the media query lists of the @media rules within the
+// given at-rule conditions.
+func mediaQueries(conditions []string) []string {
+	var out []string
+	for _, cond := range conditions {
+		name, rest := splitAtRule(cond)
+		if name == "media" {
+			out = append(out, rest)
+		}
+	}
+	return out
+}
+
+// nonScreenMediaTypes are the media types that never apply to a page rendered
+// on a screen.
+var nonScreenMediaTypes = map[string]bool{
+	"print":  true,
+	"speech": true,
+	// Deprecated media types.
+	"aural":      true,
+	"braille":    true,
+	"embossed":   true,
+	"projection": true,
+	"tty":        true,
+	"tv":         true,
+}
+
+// isNonScreenMedia reports whether every media query of the given media query
+// list only matches non-screen media types. For example, "print" and
+// "only print and (orientation: landscape)" are non-screen media, while
+// "screen", "not print", and "(min-width: 600px)" are not.
+func isNonScreenMedia(list string) bool {
+	queries := splitTopLevel(list, ',')
+	if len(queries) == 0 {
+		return false
+	}
+	for _, query := range queries {
+		fields := strings.Fields(strings.ToLower(query))
+		if len(fields) > 0 && fields[0] == "only" {
+			fields = fields[1:]
+		}
+		if len(fields) == 0 || !nonScreenMediaTypes[fields[0]] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,180 @@
+package siteperf
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+var extractClassesTests = []struct {
+	name string
+	css  string
+	want []string
+}{
+	{
+		name: "selectors",
+		css:  ".btn{color:red} .card .title:hover{color:blue}",
+		want: []string{"btn", "card", "title"},
+	},
+	{
+		name: "string literal",
+		css:  `.icon::before{content:".foo"} .bar{content:'.baz'}`,
+		want: []string{"bar", "icon"},
+	},
+	{
+		name: "escaped quotes within string",
+		css:  `.a::before{content:"\".foo"} .b::after{content:'it\'s .bar'} .c{color:red}`,
+		want: []string{"a", "b", "c"},
+	},
+	{
+		name: "attribute selector value",
+		css:  `a[href$=".pdf"]{color:red} .link{color:blue}`,
+		want: []string{"link"},
+	},
+	{
+		name: "comment with apostrophe",
+		css:  "/*! Bootstrap's grid */.btn{color:red}.card{color:blue}",
+		want: []string{"btn", "card"},
+	},
+	{
+		name: "comment with double quote",
+		css:  "/* the \"primary\" button */\n.primary{color:red}",
+		want: []string{"primary"},
+	},
+	{
+		name: "class within comment",
+		css:  "/* .legacy is gone */ .btn{color:red}",
+		want: []string{"btn"},
+	},
+	{
+		name: "comment marker within string",
+		css:  `.a{content:"/*"} .b{color:red} .c{content:"*/"}`,
+		want: []string{"a", "b", "c"},
+	},
+	{
+		name: "escaped class name",
+		css:  `.md\:hidden{display:none} .w-1\/2{width:50%}`,
+		want: []string{"md:hidden", "w-1/2"},
+	},
+	{
+		name: "numbers",
+		css:  ".a{margin:0.5rem}",
+		want: []string{"a"},
+	},
+}
+
+func TestExtractClasses(t *testing.T) {
+	for _, tt := range extractClassesTests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExtractClasses(tt.css)
+			if err != nil {
+				t.Fatalf("ExtractClasses() failed: %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ExtractClasses() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractClassesReader(t *testing.T) {
+	for _, tt := range extractClassesTests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Reading one byte at a time puts a chunk boundary between every
+			// two bytes of the CSS.
+			r := iotest.OneByteReader(strings.NewReader(tt.css))
+			got, err := ExtractClassesReader(context.Background(), r)
+			if err != nil {
+				t.Fatalf("ExtractClassesReader() failed: %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ExtractClassesReader() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractClassesReader_commentAcrossChunks(t *testing.T) {
+	// The comment with the apostrophe starts at the end of the first chunk
+	// and ends in the second one. The apostrophe must not start a string
+	// that hides the classes of the following chunks.
+	prefix := strings.Repeat(" ", readerChunkSize-len("/*! Bootstrap"))
+	css := prefix + "/*! Bootstrap's grid */.btn{color:red}" +
+		strings.Repeat(".card{color:blue}", 2*readerChunkSize/len(".card{color:blue}"))
+
+	got, err := ExtractClassesReader(context.Background(), strings.NewReader(css))
+	if err != nil {
+		t.Fatalf("ExtractClassesReader() failed: %v", err)
+	}
+	if want := []string{"btn", "card"}; !slices.Equal(got, want) {
+		t.Errorf("ExtractClassesReader() = %q, want %q", got, want)
+	}
+}
+
+// benchmarkCSS returns the stylesheet of the "site" fixture, repeated to a
+// size of about 1 MB, like the stylesheet of a utility framework.
+func benchmarkCSS(b *testing.B) []byte {
+	b.Helper()
+	css, err := os.ReadFile("testdata/site/style.css")
+	if err != nil {
+		b.Fatalf("read fixture: %v", err)
+	}
+	return bytes.Repeat(css, (1<<20)/len(css))
+}
+
+func BenchmarkExtractClasses(b *testing.B) {
+	css := string(benchmarkCSS(b))
+	b.SetBytes(int64(len(css)))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := ExtractClasses(css); err != nil {
+			b.Fatalf("ExtractClasses() failed: %v", err)
+		}
+	}
+}
+
+func BenchmarkExtractClassesReader(b *testing.B) {
+	css := benchmarkCSS(b)
+	b.SetBytes(int64(len(css)))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := ExtractClassesReader(context.Background(), bytes.NewReader(css)); err != nil {
+			b.Fatalf("ExtractClassesReader() failed: %v", err)
+		}
+	}
+}
+
+func TestExtractClassesDetailed_whitespace(t *testing.T) {
+	tests := []struct {
+		name string
+		css  string
+		want []string
+	}{
+		{name: "escaped trailing space", css: `.btn\20{color:red}`, want: []string{"btn"}},
+		{name: "escaped leading tab", css: `.\9 card{color:red}`, want: []string{"card"}},
+		{name: "only escaped whitespace", css: `.\20{color:red} .link{color:blue}`, want: []string{"link"}},
+		{name: "line breaks between selectors", css: ".btn,\n\t.card\f.title{color:red}", want: []string{"btn", "card", "title"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			details, err := ExtractClassesDetailed(tt.css)
+			if err != nil {
+				t.Fatalf("ExtractClassesDetailed() failed: %v", err)
+			}
+			var got []string
+			for _, d := range details {
+				got = append(got, d.Name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ExtractClassesDetailed() classes = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
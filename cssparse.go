@@ -0,0 +1,552 @@
+package siteperf
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// stylesheet is the parsed representation of a CSS stylesheet. It is produced
+// by parseStylesheet and is intentionally forgiving: malformed input never
+// results in an error, but may result in missing rules.
+type stylesheet struct {
+	rules   []styleRule
+	atRules []atRule
+}
+
+// styleRule is a style rule, i.e. a selector list followed by a declaration
+// block. Nested style rules (CSS nesting) are flattened into separate rules
+// with their selectors resolved against the parent selectors.
+type styleRule struct {
+	// selector is the full selector list of the rule.
+	selector string
+
+	// selectors contains the individual selectors of the selector list.
+	selectors []string
+
+	declarations []declaration
+
+	// conditions contains the preludes of the conditional group rules (e.g.
+	// "@media print") that enclose the rule, from outermost to innermost.
+	conditions []string
+
+	// line is the 1-based line number at which the rule starts.
+	line int
+}
+
+// atRule is an at-rule with a block that is not a conditional group rule,
+// e.g. @font-face or @keyframes.
+type atRule struct {
+	// name is the lowercased name of the at-rule without the "@".
+	name    string
+	prelude string
+	block   string
+
+	conditions []string
+	line       int
+}
+
+// declaration is a single property declaration within a declaration block.
+type declaration struct {
+	property  string
+	value     string
+	important bool
+}
+
+// conditionalAtRules are the at-rules whose blocks contain regular rules.
+var conditionalAtRules = map[string]bool{
+	"media":          true,
+	"supports":       true,
+	"layer":          true,
+	"container":      true,
+	"scope":          true,
+	"document":       true,
+	"-moz-document":  true,
+	"starting-style": true,
+}
+
+type cssParser struct {
+	src   string
+	lines []int
+	sheet stylesheet
+}
+
+// parseStylesheet parses the given CSS into a stylesheet.
+func parseStylesheet(css string) *stylesheet {
+	p := &cssParser{src: stripComments(css)}
+	for i := 0; i < len(p.src); i++ {
+		if p.src[i] == '\n' {
+			p.lines = append(p.lines, i)
+		}
+	}
+	p.parseRules(0, len(p.src), nil, nil)
+	return &p.sheet
+}
+
+// lineAt returns the 1-based line number of the given offset.
+func (p *cssParser) lineAt(pos int) int {
+	return sort.SearchInts(p.lines, pos) + 1
+}
+
+// parseRules parses the rules within src[start:end]. If parents is non-empty,
+// the contents are the block of a style rule with the given selectors, and
+// the declarations found at the top level of the block are returned.
+func (p *cssParser) parseRules(start, end int, conditions, parents []string) []declaration {
+	var decls []declaration
+
+	pos := start
+	for pos < end {
+		pos = skipSpace(p.src, pos, end)
+		if pos >= end {
+			break
+		}
+
+		stop := scanUntil(p.src, pos, end, "{;}")
+		prelude := strings.TrimSpace(p.src[pos:stop])
+
+		if stop >= end || p.src[stop] != '{' {
+			// A statement without a block. Within a style rule this is a
+			// declaration; at the top level it is an at-rule like @import,
+			// which is ignored.
+			if len(parents) > 0 && prelude != "" && prelude[0] != '@' {
+				if decl, ok := parseDeclaration(prelude); ok {
+					decls = append(decls, decl)
+				}
+			}
+			pos = stop + 1
+			continue
+		}
+
+		blockStart := stop + 1
+		blockEnd := matchBrace(p.src, stop, end)
+		line := p.lineAt(pos)
+
+		if strings.HasPrefix(prelude, "@") {
+			name, rest := splitAtRule(prelude)
+			if conditionalAtRules[name] {
+				nested := append(cloneStrings(conditions), prelude)
+				decls = append(decls, p.parseRules(blockStart, blockEnd, nested, parents)...)
+			} else {
+				p.sheet.atRules = append(p.sheet.atRules, atRule{
+					name:       name,
+					prelude:    rest,
+					block:      p.src[blockStart:blockEnd],
+					conditions: cloneStrings(conditions),
+					line:       line,
+				})
+			}
+		} else {
+			selectors := resolveNesting(splitTopLevel(prelude, ','), parents)
+			index := len(p.sheet.rules)
+			p.sheet.rules = append(p.sheet.rules, styleRule{
+				selector:   strings.Join(selectors, ", "),
+				selectors:  selectors,
+				conditions: cloneStrings(conditions),
+				line:       line,
+			})
+			p.sheet.rules[index].declarations = p.parseRules(blockStart, blockEnd, conditions, selectors)
+		}
+
+		pos = blockEnd + 1
+	}
+
+	return decls
+}
+
+// parseDeclarations parses the declarations of a declaration block.
+func parseDeclarations(block string) []declaration {
+	var decls []declaration
+	for _, raw := range splitTopLevel(block, ';') {
+		if decl, ok := parseDeclaration(raw); ok {
+			decls = append(decls, decl)
+		}
+	}
+	return decls
+}
+
+func parseDeclaration(raw string) (declaration, bool) {
+	colon := scanUntil(raw, 0, len(raw), ":")
+	if colon >= len(raw) {
+		return declaration{}, false
+	}
+
+	decl := declaration{
+		property: strings.ToLower(strings.TrimSpace(raw[:colon])),
+		value:    strings.TrimSpace(raw[colon+1:]),
+	}
+	if decl.property == "" {
+		return declaration{}, false
+	}
+
+	if bang := strings.LastIndex(decl.value, "!"); bang >= 0 {
+		if strings.EqualFold(strings.TrimSpace(decl.value[bang+1:]), "important") {
+			decl.important = true
+			decl.value = strings.TrimSpace(decl.value[:bang])
+		}
+	}
+
+	return decl, true
+}
+
+// resolveNesting resolves nested selectors against the selectors of the
+// parent rule. The nesting selector "&" is replaced by the parent selector;
+// nested selectors without "&" are treated as descendants of the parent.
+func resolveNesting(selectors, parents []string) []string {
+	if len(parents) == 0 {
+		return selectors
+	}
+
+	parent := parents[0]
+	if len(parents) > 1 {
+		parent = ":is(" + strings.Join(parents, ", ") + ")"
+	}
+
+	out := make([]string, 0, len(selectors))
+	for _, sel := range selectors {
+		if strings.Contains(sel, "&") {
+			out = append(out, strings.ReplaceAll(sel, "&", parent))
+		} else {
+			out = append(out, parent+" "+sel)
+		}
+	}
+	return out
+}
+
+func splitAtRule(prelude string) (name, rest string) {
+	end := 1
+	for end < len(prelude) && isIdentChar(prelude[end]) {
+		end++
+	}
+	return strings.ToLower(prelude[1:end]), strings.TrimSpace(prelude[end:])
+}
+
+// stripComments replaces all comments in the given CSS with spaces. Line
+// breaks within comments are preserved so that line numbers stay accurate.
+func stripComments(css string) string {
+	if !strings.Contains(css, "/*") {
+		return css
+	}
+
+	var b strings.Builder
+	b.Grow(len(css))
+
+	for i := 0; i < len(css); i++ {
+		c := css[i]
+		switch {
+		case c == '"' || c == '\'':
+			end := skipString(css, i, len(css))
+			b.WriteString(css[i:end])
+			i = end - 1
+		case c == '\\' && i+1 < len(css):
+			b.WriteString(css[i : i+2])
+			i++
+		case c == '/' && i+1 < len(css) && css[i+1] == '*':
+			end := strings.Index(css[i+2:], "*/")
+			if end < 0 {
+				end = len(css)
+			} else {
+				end += i + 4
+			}
+			for _, r := range css[i:end] {
+				if r == '\n' {
+					b.WriteByte('\n')
+				} else {
+					b.WriteByte(' ')
+				}
+			}
+			i = end - 1
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
+
+// blankStrings replaces the contents of all string literals in the given CSS
+// with spaces, so that text like the ".foo" of content: "...foo" or the
+// ".pdf" of [href$=".pdf"] is not mistaken for a class. The quotes and the
+// length of the CSS are preserved. If the CSS ends within a string that is
+// not terminated by a quote or a line break, the offset of its opening quote
+// is returned, and -1 otherwise.
+func blankStrings(css string) (string, int) {
+	if !strings.ContainsAny(css, `"'`) {
+		return css, -1
+	}
+
+	b := []byte(css)
+	for i := 0; i < len(b); i++ {
+		switch c := b[i]; c {
+		case '\\':
+			i++
+		case '"', '\'':
+			start := i
+			for i++; ; i++ {
+				if i >= len(b) {
+					return string(b), start
+				}
+				if b[i] == c || b[i] == '\n' {
+					break
+				}
+				if b[i] == '\\' && i+1 < len(b) && b[i+1] != '\n' {
+					b[i] = ' '
+					i++
+				}
+				b[i] = ' '
+			}
+		}
+	}
+
+	return string(b), -1
+}
+
+// cssBlanker blanks the comments and the contents of the string literals of
+// CSS that is read in chunks, like blankStrings does for CSS as a whole. It
+// tracks whether a chunk ends within a comment, a string, or an escape
+// sequence, so that the next chunk continues where the previous one ended.
+type cssBlanker struct {
+	// quote is the opening quote of the string the last chunk ended in, or 0.
+	quote byte
+
+	comment bool
+	escape  bool
+
+	// slash reports whether the last byte outside of comments and strings
+	// was a slash, which starts a comment if it is followed by an asterisk,
+	// and star whether the last byte of a comment was an asterisk, which
+	// ends the comment if it is followed by a slash.
+	slash bool
+	star  bool
+}
+
+// blank replaces the comments and the contents of the string literals within
+// the chunk with spaces, in place. Line breaks are preserved.
+func (s *cssBlanker) blank(chunk []byte) {
+	for i, c := range chunk {
+		switch {
+		case s.comment:
+			if s.star && c == '/' {
+				s.comment = false
+			}
+			s.star = c == '*'
+			if c != '\n' {
+				chunk[i] = ' '
+			}
+
+		case s.quote != 0:
+			switch {
+			case s.escape:
+				s.escape = false
+			case c == '\\':
+				s.escape = true
+			case c == s.quote || c == '\n':
+				s.quote = 0
+				continue
+			}
+			if c != '\n' {
+				chunk[i] = ' '
+			}
+
+		case s.escape:
+			s.escape = false
+
+		case s.slash && c == '*':
+			s.comment = true
+			s.star = false
+			s.slash = false
+			chunk[i] = ' '
+			if i > 0 {
+				chunk[i-1] = ' '
+			}
+
+		default:
+			switch c {
+			case '\\':
+				s.escape = true
+			case '"', '\'':
+				s.quote = c
+			}
+			s.slash = c == '/'
+		}
+	}
+}
+
+// scanUntil returns the offset of the first of the given stop characters in
+// s[pos:end] that is not part of a string, an escape sequence, or a nested
+// parenthesis or bracket. If none is found, end is returned.
+func scanUntil(s string, pos, end int, stops string) int {
+	depth := 0
+	for i := pos; i < end; i++ {
+		c := s[i]
+		switch {
+		case c == '\\':
+			i++
+		case c == '"' || c == '\'':
+			i = skipString(s, i, end) - 1
+		case c == '(' || c == '[':
+			depth++
+		case (c == ')' || c == ']') && depth > 0:
+			depth--
+		case depth == 0 && strings.IndexByte(stops, c) >= 0:
+			return i
+		}
+	}
+	return end
+}
+
+// matchBrace returns the offset of the "}" that closes the "{" at the given
+// offset, or end if the block is not closed.
+func matchBrace(s string, open, end int) int {
+	depth := 0
+	for i := open; i < end; i++ {
+		switch c := s[i]; {
+		case c == '\\':
+			i++
+		case c == '"' || c == '\'':
+			i = skipString(s, i, end) - 1
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return end
+}
+
+// skipString returns the offset directly after the string that starts at the
+// given offset. Unterminated strings end at the next line break.
+func skipString(s string, start, end int) int {
+	quote := s[start]
+	for i := start + 1; i < end; i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		case '\n':
+			return i
+		}
+	}
+	return end
+}
+
+func skipSpace(s string, pos, end int) int {
+	for pos < end && isSpace(s[pos]) {
+		pos++
+	}
+	return pos
+}
+
+// splitTopLevel splits s at every occurrence of sep that is not part of a
+// string or a nested parenthesis or bracket. Empty parts are omitted.
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	for pos := 0; pos <= len(s); {
+		end := scanUntil(s, pos, len(s), string(sep))
+		if part := strings.TrimSpace(s[pos:end]); part != "" {
+			parts = append(parts, part)
+		}
+		pos = end + 1
+	}
+	return parts
+}
+
+func cloneStrings(s []string) []string {
+	if len(s) == 0 {
+		return nil
+	}
+	return append([]string(nil), s...)
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isIdentChar(c byte) bool {
+	return c == '-' || c == '_' || c >= 0x80 ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// readIdent reads a CSS identifier starting at the given offset and returns
+// it with all escape sequences resolved, along with the offset directly after
+// the identifier.
+func readIdent(s string, pos int) (string, int) {
+	var b strings.Builder
+	for pos < len(s) {
+		c := s[pos]
+		switch {
+		case c == '\\' && pos+1 < len(s) && s[pos+1] != '\n':
+			r, next := readEscape(s, pos+1)
+			b.WriteRune(r)
+			pos = next
+		case isIdentChar(c):
+			if c < utf8.RuneSelf {
+				b.WriteByte(c)
+				pos++
+			} else {
+				r, size := utf8.DecodeRuneInString(s[pos:])
+				b.WriteRune(r)
+				pos += size
+			}
+		default:
+			return b.String(), pos
+		}
+	}
+	return b.String(), pos
+}
+
+// readEscape reads the escape sequence that starts after the backslash at the
+// given offset and returns the escaped rune and the offset after the sequence.
+func readEscape(s string, pos int) (rune, int) {
+	end := pos
+	for end < len(s) && end-pos < 6 && isHex(s[end]) {
+		end++
+	}
+
+	if end == pos {
+		r, size := utf8.DecodeRuneInString(s[pos:])
+		return r, pos + size
+	}
+
+	code, _ := strconv.ParseUint(s[pos:end], 16, 32)
+	if end < len(s) && isSpace(s[end]) {
+		end++
+	}
+	if code == 0 || code > utf8.MaxRune {
+		return utf8.RuneError, end
+	}
+	return rune(code), end
+}
+
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// unquote removes the quotes around a CSS string and resolves its escape
+// sequences. Unquoted input is returned with surrounding whitespace removed.
+func unquote(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || (s[0] != '"' && s[0] != '\'') || s[len(s)-1] != s[0] {
+		return s
+	}
+	s = s[1 : len(s)-1]
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			r, next := readEscape(s, i+1)
+			b.WriteRune(r)
+			i = next - 1
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,106 @@
+package siteperf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+
+	"github.com/go-rod/rod"
+)
+
+// DefinedClasses crawls the website of the Finder and returns a sorted list of
+// unique class names that are defined by the stylesheets linked from the
+// crawled pages. If inline styles are scanned (see [WithScanInlineStyles]), the
+// classes defined within the <style> elements of the crawled pages are
+// included as well. Stylesheets that cannot be fetched are logged and skipped.
+func (f *Finder) DefinedClasses(ctx context.Context) ([]string, error) {
+	result, err := f.crawl(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("crawl: %w", err)
+	}
+
+	var classes []string
+	fetched := make(map[string]bool)
+	for _, page := range result.pages {
+		for _, href := range page.stylesheets {
+			if fetched[href] {
+				continue
+			}
+			fetched[href] = true
+
+			css, err := f.fetch(ctx, href)
+			if err != nil {
+				f.log.Warn("Failed to fetch stylesheet", "url", href, "err", err)
+				continue
+			}
+
+			defined, err := ExtractClasses(string(css))
+			if err != nil {
+				f.log.Warn("Failed to extract classes from stylesheet", "url", href, "err", err)
+				continue
+			}
+			classes = append(classes, defined...)
+		}
+
+		for _, css := range page.inlineStyles {
+			defined, err := ExtractClasses(css)
+			if err != nil {
+				f.log.Warn("Failed to extract classes from inline style", "url", page.url, "err", err)
+				continue
+			}
+			classes = append(classes, defined...)
+		}
+	}
+
+	classes = unique(classes)
+	slices.Sort(classes)
+
+	return classes, nil
+}
+
+func (f *Finder) findStylesheets(page *rod.Page, pageUrl string) ([]string, error) {
+	base, err := url.Parse(pageUrl)
+	if err != nil {
+		return nil, fmt.Errorf("parse page URL: %w", err)
+	}
+
+	links, err := extractAttributes(page, `link[rel~="stylesheet"][href]`, "href")
+	if err != nil {
+		return nil, fmt.Errorf("get stylesheet links: %w", err)
+	}
+
+	var out []string
+	for _, link := range links {
+		href := link.Attrs["href"]
+
+		u, err := base.Parse(href)
+		if err != nil {
+			f.log.Warn("Failed to parse stylesheet URL", "href", href, "err", err)
+			continue
+		}
+
+		if u.Scheme != "http" && u.Scheme != "https" {
+			continue
+		}
+
+		out = append(out, u.String())
+	}
+
+	return out, nil
+}
+
+func (f *Finder) extractInlineStyles(page *rod.Page) ([]string, error) {
+	res, err := page.Eval(`() => Array.from(document.querySelectorAll("style"), (el) => el.textContent)`)
+	if err != nil {
+		return nil, fmt.Errorf("get contents of <style> elements: %w", err)
+	}
+
+	var styles []string
+	if err := json.Unmarshal([]byte(res.Value.JSON("", "")), &styles); err != nil {
+		return nil, fmt.Errorf("decode contents of <style> elements: %w", err)
+	}
+
+	return styles, nil
+}
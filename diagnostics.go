@@ -0,0 +1,55 @@
+package siteperf
+
+import "fmt"
+
+// DiagnosticCode identifies the kind of a [Diagnostic].
+type DiagnosticCode string
+
+const (
+	// DiagnosticNoMatch means that classes were found on the crawled pages,
+	// but none of them is among the defined classes. This usually means that
+	// the wrong CSS file was audited, or that the class names are rewritten
+	// at build time (see [WithClassManifest]).
+	DiagnosticNoMatch = DiagnosticCode("no-match")
+
+	// DiagnosticNoPageClasses means that no classes were found on any of the
+	// crawled pages. This usually means that the crawl failed, e.g. because
+	// the pages are rendered by scripts that did not run, or because the
+	// crawler was served an error or login page.
+	DiagnosticNoPageClasses = DiagnosticCode("no-page-classes")
+)
+
+// Diagnostic is a likely problem with the setup of an audit that makes its
+// result meaningless, like an audit that reports every defined class as
+// unused because the wrong CSS file was audited.
+type Diagnostic struct {
+	Code    DiagnosticCode `json:"code"`
+	Message string         `json:"message"`
+}
+
+// diagnose returns the diagnostics of the audit. An audit is only diagnosed if
+// not a single one of its defined classes was found on the crawled pages,
+// because some unused classes are expected in any real stylesheet.
+func (a Audit) diagnose() []Diagnostic {
+	if a.Coverage.Defined == 0 || a.Coverage.Used > 0 {
+		return nil
+	}
+
+	if len(a.Usage) == 0 {
+		return []Diagnostic{{
+			Code: DiagnosticNoPageClasses,
+			Message: fmt.Sprintf(
+				"none of the %d crawled pages contains any class, so all %d defined classes are reported as unused; check that the pages were rendered (e.g. not an error or login page)",
+				a.Crawl.Visited, a.Coverage.Defined,
+			),
+		}}
+	}
+
+	return []Diagnostic{{
+		Code: DiagnosticNoMatch,
+		Message: fmt.Sprintf(
+			"none of the %d defined classes matches any of the %d classes on the crawled pages; check that the right CSS file is audited and that class names are not rewritten at build time (see the class manifest)",
+			a.Coverage.Defined, len(a.Usage),
+		),
+	}}
+}
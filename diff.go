@@ -0,0 +1,78 @@
+package siteperf
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// EnvironmentDiff is the difference in class usage between a staging and a
+// production environment of the same website.
+type EnvironmentDiff struct {
+	// OnlyInStaging contains the classes that are used on the staging
+	// environment, but not on production, sorted by name.
+	OnlyInStaging []string `json:"onlyInStaging"`
+
+	// OnlyInProduction contains the classes that are used on production, but
+	// not on the staging environment, sorted by name. After a deploy of the
+	// staging environment, these classes would no longer be used, which may
+	// indicate a dropped component.
+	OnlyInProduction []string `json:"onlyInProduction"`
+}
+
+// DiffEnvironments crawls the staging and production root URLs concurrently
+// and returns the classes that are used in only one of the environments. Both
+// crawls use the same page limit and options. If either crawl fails, an error
+// is returned.
+func DiffEnvironments(ctx context.Context, stagingURL, productionURL string, pageLimit int, opts ...Option) (EnvironmentDiff, error) {
+	staging, err := New(stagingURL, pageLimit, opts...)
+	if err != nil {
+		return EnvironmentDiff{}, fmt.Errorf("staging: %w", err)
+	}
+
+	production, err := New(productionURL, pageLimit, opts...)
+	if err != nil {
+		return EnvironmentDiff{}, fmt.Errorf("production: %w", err)
+	}
+
+	var (
+		wg                        sync.WaitGroup
+		stagingUsed, prodUsed     map[string]int
+		stagingErr, productionErr error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		stagingUsed, stagingErr = staging.FindUsed(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		prodUsed, productionErr = production.FindUsed(ctx)
+	}()
+	wg.Wait()
+
+	if stagingErr != nil {
+		return EnvironmentDiff{}, fmt.Errorf("crawl staging: %w", stagingErr)
+	}
+	if productionErr != nil {
+		return EnvironmentDiff{}, fmt.Errorf("crawl production: %w", productionErr)
+	}
+
+	return EnvironmentDiff{
+		OnlyInStaging:    usedOnlyIn(stagingUsed, prodUsed),
+		OnlyInProduction: usedOnlyIn(prodUsed, stagingUsed),
+	}, nil
+}
+
+// usedOnlyIn returns the sorted classes that are used in a, but not in b.
+func usedOnlyIn(a, b map[string]int) []string {
+	out := make([]string, 0)
+	for class, count := range a {
+		if count > 0 && b[class] <= 0 {
+			out = append(out, class)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
@@ -0,0 +1,133 @@
+package siteperf
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// maxEffectiveElements is the maximum number of elements per page whose
+// matched styles are checked by [Finder.EffectiveUsage].
+const maxEffectiveElements = 25
+
+// EffectiveUsage crawls the website of the Finder and reports whether a CSS
+// rule that targets the given class actually applies to at least one element
+// that has the class. In contrast to [Finder.FindUnused], which only checks
+// that the class is present in the markup, EffectiveUsage uses the matched
+// styles of the browser (CDP's CSS.getMatchedStylesForNode) to confirm that
+// the class is actually styled. Rules of the user-agent stylesheet and rules
+// without declarations are ignored. The crawl stops as soon as the usage is
+// confirmed.
+func (f *Finder) EffectiveUsage(ctx context.Context, class string) (bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var found atomic.Bool
+	_, err := f.crawl(ctx, func(ctx context.Context, page *rod.Page, result *pageResult) error {
+		if found.Load() || !hasUsedClass(result.classes, class) {
+			return nil
+		}
+
+		ok, err := matchesClassRule(page, class)
+		if err != nil {
+			return fmt.Errorf("check matched styles of %q: %w", class, err)
+		}
+
+		if ok {
+			found.Store(true)
+			cancel()
+		}
+
+		return nil
+	})
+
+	if found.Load() {
+		return true, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("find used classes: %w", err)
+	}
+
+	return false, ctx.Err()
+}
+
+// matchesClassRule reports whether a non-empty author rule with a selector
+// that references the given class matches one of the elements on the page
+// that have the class.
+func matchesClassRule(page *rod.Page, class string) (bool, error) {
+	elements, err := page.ElementsByJS(rod.Eval(`(c) => Array.from(document.getElementsByClassName(c))`, class))
+	if err != nil {
+		return false, fmt.Errorf("find elements: %w", err)
+	}
+	if len(elements) == 0 {
+		return false, nil
+	}
+
+	defer page.EnableDomain(proto.DOMEnable{})()
+	defer page.EnableDomain(proto.CSSEnable{})()
+
+	// Node ids can only be requested after the document was requested.
+	if _, err := (proto.DOMGetDocument{}).Call(page); err != nil {
+		return false, fmt.Errorf("get document: %w", err)
+	}
+
+	for _, el := range elements[:min(len(elements), maxEffectiveElements)] {
+		node, err := proto.DOMRequestNode{ObjectID: el.Object.ObjectID}.Call(page)
+		if err != nil {
+			return false, fmt.Errorf("request node: %w", err)
+		}
+
+		styles, err := proto.CSSGetMatchedStylesForNode{NodeID: node.NodeID}.Call(page)
+		if err != nil {
+			return false, fmt.Errorf("get matched styles: %w", err)
+		}
+
+		for _, match := range styles.MatchedCSSRules {
+			if ruleTargetsClass(match, class) {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// ruleTargetsClass reports whether one of the matching selectors of the rule
+// references the given class and the rule contributes any declarations.
+func ruleTargetsClass(match *proto.CSSRuleMatch, class string) bool {
+	rule := match.Rule
+	if rule == nil || rule.SelectorList == nil || rule.Origin == proto.CSSStyleSheetOriginUserAgent {
+		return false
+	}
+	if rule.Style == nil || len(rule.Style.CSSProperties) == 0 {
+		return false
+	}
+
+	for _, i := range match.MatchingSelectors {
+		if i < 0 || i >= len(rule.SelectorList.Selectors) {
+			continue
+		}
+		for _, sel := range parseSelectorList(rule.SelectorList.Selectors[i].Text) {
+			for _, ref := range selectorClassRefs(sel, false, false) {
+				if ref.name == class {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+func hasUsedClass(classes []usedClass, class string) bool {
+	for _, uc := range classes {
+		if uc.class == class && uc.count > 0 {
+			return true
+		}
+	}
+	return false
+}
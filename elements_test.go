@@ -0,0 +1,72 @@
+package siteperf
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// benchmarkElements is the number of elements with a class attribute on the
+// page of BenchmarkExtractAttributes.
+const benchmarkElements = 10000
+
+// BenchmarkExtractAttributes compares the extraction of the class attributes
+// of a page with 10,000 elements within a single evaluation (extractAttributes)
+// with one CDP round-trip per element.
+func BenchmarkExtractAttributes(b *testing.B) {
+	requireBrowser(b)
+
+	var body strings.Builder
+	for i := 0; i < benchmarkElements; i++ {
+		fmt.Fprintf(&body, `<div class="item item--%d"></div>`, i%100)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "<!DOCTYPE html><html><body>%s</body></html>", body.String())
+	}))
+	b.Cleanup(srv.Close)
+
+	browser := rod.New()
+	if err := browser.Connect(); err != nil {
+		b.Fatalf("connect to browser: %v", err)
+	}
+	b.Cleanup(func() { browser.Close() })
+
+	page, err := browser.Page(proto.TargetCreateTarget{URL: srv.URL})
+	if err != nil {
+		b.Fatalf("open page: %v", err)
+	}
+	if err := page.WaitLoad(); err != nil {
+		b.Fatalf("wait for page: %v", err)
+	}
+
+	b.Run("batched", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			elements, err := extractAttributes(page, "[class]", "class")
+			if err != nil {
+				b.Fatalf("extractAttributes() failed: %v", err)
+			}
+			if len(elements) != benchmarkElements {
+				b.Fatalf("extractAttributes() returned %d elements, want %d", len(elements), benchmarkElements)
+			}
+		}
+	})
+
+	b.Run("per-element", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			elements, err := page.Elements("[class]")
+			if err != nil {
+				b.Fatalf("find elements: %v", err)
+			}
+			for _, el := range elements {
+				if _, err := el.Attribute("class"); err != nil {
+					b.Fatalf("get class attribute: %v", err)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,112 @@
+package siteperf
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// ReportEncoder encodes an [Audit] into a report format. Custom formats can be
+// made available by name using [RegisterReportEncoder].
+type ReportEncoder interface {
+	Encode(w io.Writer, audit Audit) error
+}
+
+// ReportEncoderFunc is a function that implements [ReportEncoder].
+type ReportEncoderFunc func(w io.Writer, audit Audit) error
+
+// Encode calls fn(w, audit).
+func (fn ReportEncoderFunc) Encode(w io.Writer, audit Audit) error {
+	return fn(w, audit)
+}
+
+var (
+	encodersMux sync.RWMutex
+	encoders    = map[string]ReportEncoder{
+		"json": ReportEncoderFunc(encodeJSONReport),
+		"csv":  ReportEncoderFunc(encodeCSVReport),
+		"text": ReportEncoderFunc(WriteReport),
+	}
+)
+
+// RegisterReportEncoder registers the encoder of a report format under the
+// given name, replacing any encoder previously registered under that name.
+// The built-in formats are "json" (the indented JSON of the audit), "csv"
+// (one row per class with its usage count and whether it is unused), and
+// "text" (see [WriteReport]).
+func RegisterReportEncoder(format string, enc ReportEncoder) {
+	encodersMux.Lock()
+	defer encodersMux.Unlock()
+	encoders[format] = enc
+}
+
+// LookupReportEncoder returns the encoder registered under the given format
+// name.
+func LookupReportEncoder(format string) (ReportEncoder, bool) {
+	encodersMux.RLock()
+	defer encodersMux.RUnlock()
+	enc, ok := encoders[format]
+	return enc, ok
+}
+
+// ReportFormats returns the sorted names of all registered report formats.
+func ReportFormats() []string {
+	encodersMux.RLock()
+	defer encodersMux.RUnlock()
+
+	formats := make([]string, 0, len(encoders))
+	for format := range encoders {
+		formats = append(formats, format)
+	}
+	slices.Sort(formats)
+
+	return formats
+}
+
+// EncodeReport encodes the audit into the given report format.
+func EncodeReport(w io.Writer, format string, audit Audit) error {
+	enc, ok := LookupReportEncoder(format)
+	if !ok {
+		return fmt.Errorf("unknown report format %q", format)
+	}
+	return enc.Encode(w, audit)
+}
+
+func encodeJSONReport(w io.Writer, audit Audit) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(audit)
+}
+
+// encodeCSVReport writes the used classes and the unused classes of the audit,
+// sorted by name.
+func encodeCSVReport(w io.Writer, audit Audit) error {
+	counts := make(map[string]int, len(audit.Usage)+len(audit.Unused))
+	for class, count := range audit.Usage {
+		counts[class] = count
+	}
+	unused := make(map[string]bool, len(audit.Unused))
+	for _, class := range audit.Unused {
+		unused[class] = true
+		counts[class] += 0
+	}
+
+	classes := make([]string, 0, len(counts))
+	for class := range counts {
+		classes = append(classes, class)
+	}
+	slices.Sort(classes)
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"class", "count", "unused"})
+	for _, class := range classes {
+		cw.Write([]string{class, strconv.Itoa(counts[class]), strconv.FormatBool(unused[class])})
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
@@ -0,0 +1,69 @@
+package siteperf
+
+import (
+	"errors"
+	"fmt"
+)
+
+// CrawlStage is the stage of a crawl at which a [CrawlError] occurred.
+type CrawlStage string
+
+const (
+	// StagePreflight is the reachability check of the root URL (see
+	// [WithPreflight]).
+	StagePreflight = CrawlStage("preflight")
+
+	// StageConnect is the launch of, and connection to, the browser.
+	StageConnect = CrawlStage("connect")
+
+	// StageLogin is the login script that runs before the crawl (see
+	// [WithLoginScript]).
+	StageLogin = CrawlStage("login")
+
+	// StageOpen is the opening of a page in the browser.
+	StageOpen = CrawlStage("open")
+
+	// StageLoad is the loading of a page, up to the point where it is stable.
+	StageLoad = CrawlStage("load")
+
+	// StageExtract is the extraction of the classes of a page.
+	StageExtract = CrawlStage("extract")
+
+	// StageLinks is the discovery of the links of a page.
+	StageLinks = CrawlStage("links")
+)
+
+// ErrTooManyFailures is returned when a crawl is aborted because the ratio of
+// pages that could not be visited exceeds the configured failure threshold
+// (see [WithFailureThreshold]).
+var ErrTooManyFailures = errors.New("too many pages failed")
+
+// CrawlError is returned when a crawl fails. It carries the URL that was
+// being processed and the stage of the crawl at which the failure occurred.
+// Use [errors.As] to access it from the errors returned by [Finder] methods.
+//
+// Failures of individual pages are logged and skipped. They only fail the
+// crawl if not even the root page could be crawled.
+type CrawlError struct {
+	URL   string
+	Stage CrawlStage
+	Err   error
+}
+
+func (err *CrawlError) Error() string {
+	return fmt.Sprintf("%s %s: %v", err.Stage, err.URL, err.Err)
+}
+
+func (err *CrawlError) Unwrap() error {
+	return err.Err
+}
+
+// StatusError is the error of a [CrawlError] for a page that responded with a
+// status code that is not accepted (see [WithAcceptStatus]).
+type StatusError struct {
+	StatusCode int
+}
+
+func (err *StatusError) Error() string {
+	return fmt.Sprintf("unaccepted status code %d", err.StatusCode)
+}
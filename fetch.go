@@ -0,0 +1,153 @@
+package siteperf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// fetch performs a GET request to the given URL and returns the response body.
+// Responses with a non-2xx status code are reported as errors.
+func (f *Finder) fetch(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header = f.requestHeader()
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(limitBody(resp.Body, f.maxResponseSize))
+}
+
+// ErrResponseTooLarge is returned when the body of a response that is fetched
+// directly over HTTP exceeds the configured maximum response size (see
+// [WithMaxResponseSize]).
+var ErrResponseTooLarge = errors.New("response body exceeds maximum size")
+
+// limitBody returns a reader that reads at most max bytes from r and fails
+// with [ErrResponseTooLarge] if r contains more data. If max is not positive,
+// r is returned unchanged.
+func limitBody(r io.Reader, max int64) io.Reader {
+	if max <= 0 {
+		return r
+	}
+	return &maxBytesReader{r: r, remaining: max, max: max}
+}
+
+type maxBytesReader struct {
+	r         io.Reader
+	remaining int64
+	max       int64
+}
+
+func (r *maxBytesReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		// Check whether the body has more data than allowed.
+		var probe [1]byte
+		n, err := r.r.Read(probe[:])
+		if n > 0 {
+			return 0, fmt.Errorf("%w (%d bytes)", ErrResponseTooLarge, r.max)
+		}
+		return 0, err
+	}
+
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+	n, err := r.r.Read(p)
+	r.remaining -= int64(n)
+	return n, err
+}
+
+// checkReachable checks that the root URL of the Finder is reachable. It sends a
+// HEAD request to the root URL, falling back to GET if the server does not
+// support HEAD, and returns an error if the request fails or the response has
+// a status code other than 2xx or 3xx.
+func (f *Finder) checkReachable(ctx context.Context) error {
+	status, err := f.probe(ctx, http.MethodHead)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = f.probe(ctx, http.MethodGet)
+	}
+	if err != nil {
+		return fmt.Errorf("root URL %q is unreachable: %w", f.rootURL, err)
+	}
+	if status < 200 || status > 399 {
+		return fmt.Errorf("root URL %q responded with status code %d", f.rootURL, status)
+	}
+	return nil
+}
+
+func (f *Finder) probe(ctx context.Context, method string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, f.rootURL.String(), nil)
+	if err != nil {
+		return 0, fmt.Errorf("create request: %w", err)
+	}
+	req.Header = f.requestHeader()
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
+
+// requestHeader returns the header of the requests that are sent directly
+// over HTTP instead of by the browser, so that they request the same variant
+// of a page as the browser does (see [WithAcceptLanguage]).
+func (f *Finder) requestHeader() http.Header {
+	header := make(http.Header)
+	if f.acceptLanguage != "" {
+		header.Set("Accept-Language", f.acceptLanguage)
+	}
+	return header
+}
+
+// modifiedAfter reports whether the page with the given URL was modified after
+// the configured time (see [WithModifiedSince]). It sends a HEAD request with
+// an If-Modified-Since header and checks the Last-Modified header of the
+// response. Pages whose modification time is unknown, e.g. because the server
+// does not send a Last-Modified header or the request fails, are assumed to be
+// modified.
+func (f *Finder) modifiedAfter(ctx context.Context, pageUrl string) bool {
+	if f.modifiedSince.IsZero() {
+		return true
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, pageUrl, nil)
+	if err != nil {
+		return true
+	}
+	req.Header = f.requestHeader()
+	req.Header.Set("If-Modified-Since", f.modifiedSince.UTC().Format(http.TimeFormat))
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		f.log.Warn("Failed to check modification time", "url", pageUrl, "err", err)
+		return true
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return false
+	}
+
+	lastModified, err := http.ParseTime(resp.Header.Get("Last-Modified"))
+	if err != nil {
+		return true
+	}
+
+	return lastModified.After(f.modifiedSince)
+}
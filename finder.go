@@ -2,9 +2,12 @@ package siteperf
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math"
+	"math/rand"
+	"net/http"
 	"net/url"
 	"runtime"
 	"slices"
@@ -14,6 +17,10 @@ import (
 
 	"github.com/bounoable/siteperf/internal/plog"
 	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/proto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Finder locates unused CSS classes within a website starting from a given URL
@@ -24,26 +31,116 @@ import (
 // resources. It operates with a customizable degree of concurrency, determined
 // by available CPU resources, to efficiently process multiple pages in
 // parallel.
+//
+// A Finder can be reused for any number of crawls, also concurrently. The
+// state of a crawl, like the visited pages, the robots.txt rules, and the
+// browser, is created when the crawl starts and discarded when it ends, so no
+// state leaks from one crawl into the next. The configuration of the Finder,
+// including its root URL, is never modified by a crawl. The only state that
+// outlives a crawl is the page cache (see [WithCache]), which is meant to be
+// shared between crawls, the WARC archive (see [WithWARCOutput]), and the live
+// counters of [Finder.Stats], which can be cleared using [Finder.Reset].
 type Finder struct {
 	rootURL   *url.URL
 	pageLimit int
 	log       *slog.Logger
+	client    *http.Client
+
+	scanInlineStyles bool
+	pageWeights      map[string]float64
+	randomOrder      bool
+	randomSeed       int64
+	preflight        bool
+
+	attributeSelectors []AttributeSelector
+	cache              *pageCache
+	includeNonScreen   bool
+	respectNofollow    bool
+	fragmentMode       FragmentMode
+	scriptMarkupTypes  []string
+	maxResponseSize    int64
+	respectRobots      bool
+	crawlDelay         time.Duration
+	ignoreHidden       bool
+
+	// classManifest maps the class names found in the DOM to the source class
+	// names they were generated from.
+	classManifest map[string][]string
+	bfs           bool
+
+	dropInvalidClasses bool
+	onlyURLs           map[string]bool
+	webSocketQuiet     time.Duration
+	acceptedStatus     []int
+	warc               *warcWriter
+
+	caseInsensitivePrefixes []string
+	loadEvent               LoadEvent
+	maxDuration             time.Duration
+	prepareScripts          []string
+	expandAll               bool
+	failureThreshold        float64
+	loginScript             func(*rod.Page) error
+	allowedHosts            map[string]bool
+	perHostConcurrency      int
+	navigations             chan struct{}
+	onNewClass              func(class string)
+	acceptLanguage          string
+	concurrency             int
+	canonicalizeQuery       func(url.Values) url.Values
+	browserReconnects       int
+	ampPrefixes             []string
+	userDataDir             string
+	dedupByStructure        bool
+	svgUseReferences        bool
+	includeFrames           bool
+	shouldCrawl             func(*url.URL, int) bool
+	extractTimeout          time.Duration
+	ignoredClasses          *ignoredClasses
+	modifiedSince           time.Time
+	respectMetaRobots       bool
+	elementSample           int
+	tracerProvider          trace.TracerProvider
+	roleSelector            string
+	bloomVisited            int
+	bloomFPRate             float64
+
+	counters *crawlCounters
 }
 
 // New initializes a new Finder with the specified root URL and page limit,
-// logging under the "Finder" namespace. It returns a pointer to the newly
-// created Finder and any error that occurred during its creation, such as an
-// invalid root URL.
-func New(rootURL string, pageLimit int) (*Finder, error) {
+// logging under the "Finder" namespace. Additional behavior can be configured
+// by passing [Option]s. It returns a pointer to the newly created Finder and
+// any error that occurred during its creation, such as an invalid root URL.
+func New(rootURL string, pageLimit int, opts ...Option) (*Finder, error) {
 	u, err := url.Parse(rootURL)
 	if err != nil {
 		return nil, err
 	}
-	return &Finder{
+	f := &Finder{
 		rootURL:   u,
 		pageLimit: pageLimit,
 		log:       plog.New("Finder"),
-	}, nil
+		client:    http.DefaultClient,
+		counters:  &crawlCounters{},
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	if f.cache != nil {
+		f.cache.config = f.extractionConfig()
+	}
+	return f, nil
+}
+
+// Reset clears the state that the Finder accumulates across crawls, so that
+// the next crawl starts as if the Finder were new: the live counters of
+// [Finder.Stats] are zeroed. The page cache and the WARC archive are files
+// that are owned by the caller and are left untouched; configure a different
+// cache directory or archive for crawls of unrelated sites. Reset does nothing
+// while a crawl of the Finder is running.
+func (f *Finder) Reset() {
+	f.counters.reset()
 }
 
 // FindUnused identifies which of the provided CSS class names are not being
@@ -53,46 +150,483 @@ func New(rootURL string, pageLimit int) (*Finder, error) {
 // If an error occurs during the search process, it also returns an error
 // detailing what went wrong.
 func (f *Finder) FindUnused(ctx context.Context, classes []string) ([]string, error) {
-	used, err := f.findUsed(ctx)
+	used, err := f.FindUsed(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("find used classes: %w", err)
 	}
 
-	unused := filter(classes, func(s string) bool {
-		return !slices.ContainsFunc(used, func(uc usedClass) bool {
-			return uc.class == s && uc.count > 0
-		})
+	return f.FindUnusedFromUsed(used, classes), nil
+}
+
+// FindUsed crawls the website of the Finder and returns a map of every class
+// found on the crawled pages to the number of elements it was found on. The
+// result can be merged with the results of other crawls using [MergeUsed] and
+// passed to [Finder.FindUnusedFromUsed].
+func (f *Finder) FindUsed(ctx context.Context) (map[string]int, error) {
+	result, err := f.crawl(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return result.usedCounts(), nil
+}
+
+// FindUnusedFromUsed returns the provided classes that are not contained in
+// the given used-class counts, in the same order as they were provided. It
+// does not crawl the website, which allows to compute the unused classes over
+// the union of multiple independent crawls, e.g. one per locale or machine.
+// Ignored classes (see [WithIgnoreClasses]) are never returned.
+func (f *Finder) FindUnusedFromUsed(used map[string]int, classes []string) []string {
+	return unusedBelow(f, used, classes, 1)
+}
+
+// unusedBelow returns the classes that are not ignored (see
+// [WithIgnoreClasses]) and whose usage is below the threshold, in the order
+// in which they were provided. The usage is looked up by the folded class
+// names (see [WithCaseInsensitivePrefixes]).
+func unusedBelow[N int | float64](f *Finder, usage map[string]N, classes []string, threshold N) []string {
+	classes = f.withoutIgnored(classes)
+
+	if len(f.caseInsensitivePrefixes) > 0 {
+		folded := make(map[string]N, len(usage))
+		for class, n := range usage {
+			folded[f.foldClass(class)] += n
+		}
+		usage = folded
+	}
+
+	return filter(classes, func(s string) bool {
+		return usage[f.foldClass(s)] < threshold
 	})
+}
 
-	return unused, nil
+// foldClass returns the lowercased class if it starts with one of the
+// case-insensitive prefixes (see [WithCaseInsensitivePrefixes]), and the
+// class as is otherwise.
+func (f *Finder) foldClass(class string) string {
+	for _, prefix := range f.caseInsensitivePrefixes {
+		if len(class) >= len(prefix) && strings.EqualFold(class[:len(prefix)], prefix) {
+			return strings.ToLower(class)
+		}
+	}
+	return class
+}
+
+// MergeUsed merges the given used-class counts, as returned by
+// [Finder.FindUsed], into a single map by summing the counts of each class.
+func MergeUsed(sets ...map[string]int) map[string]int {
+	out := make(map[string]int)
+	for _, set := range sets {
+		for class, count := range set {
+			out[class] += count
+		}
+	}
+	return out
 }
 
 type usedClass struct {
 	class string
 	count int
+
+	// rootCount is the number of occurrences on the root <html> and <body>
+	// elements. It is always less than or equal to count.
+	rootCount int
+
+	// hiddenCount is the number of occurrences within hidden subtrees, which
+	// are not included in count. It is only tracked if hidden elements are
+	// ignored (see [WithIgnoreHidden]).
+	hiddenCount int
+
+	// tags contains the sorted tag names of the visible elements the class
+	// was found on.
+	tags []string
+}
+
+// hiddenOnly reports whether the class was only found within hidden subtrees.
+func (uc usedClass) hiddenOnly() bool {
+	return uc.count == 0 && uc.hiddenCount > 0
+}
+
+func (uc usedClass) rootOnly() bool {
+	return uc.count > 0 && uc.count == uc.rootCount
+}
+
+// pageResult contains the data that was extracted from a single page.
+type pageResult struct {
+	url     string
+	classes []usedClass
+
+	// finalURL is the URL of the page after all redirects.
+	finalURL string
+
+	// status is the status code of the response of the page, or 0 if it is
+	// unknown. loadTime is the time it took to open and load the page.
+	status   int
+	loadTime time.Duration
+
+	// cached reports whether the result was loaded from the cache (see
+	// [WithCache]) instead of being rendered.
+	cached bool
+
+	// duplicate reports whether the page has the same structure as a page
+	// that was already extracted (see [WithDedupByStructure]), in which case
+	// only its links are set.
+	duplicate bool
+
+	// sampled reports whether the classes of the page are based on a random
+	// sample of its elements (see [WithElementSample]).
+	sampled bool
+
+	// restricted reports whether the page declares meta robots directives
+	// that were respected (see [WithRespectMetaRobots]), in which case its
+	// classes or links may be missing.
+	restricted bool
+
+	// stylesheets contains the absolute URLs of the stylesheets that are
+	// linked by the page.
+	stylesheets []string
+
+	// inlineStyles contains the contents of the <style> elements of the page.
+	// It is only populated if inline styles are scanned.
+	inlineStyles []string
+
+	// attributes maps the configured attribute selectors to the number of
+	// elements on the page that match them.
+	attributes map[string]int
+
+	// links contains the URLs of the links on the page that point to the host
+	// of the root URL, whether or not they have been visited.
+	links []*url.URL
+}
+
+// crawlResult contains the results of all pages visited during a crawl.
+type crawlResult struct {
+	pages   []pageResult
+	summary crawlSummary
+}
+
+// usedCounts returns the used classes of all pages as a map of class names to
+// the number of elements they were found on.
+func (r *crawlResult) usedCounts() map[string]int {
+	out := make(map[string]int)
+	for _, page := range r.pages {
+		for _, class := range page.classes {
+			if class.count > 0 {
+				out[class.class] += class.count
+			}
+		}
+	}
+	return out
+}
+
+// used merges the classes of all pages into a single list of used classes.
+func (r *crawlResult) used() []usedClass {
+	tmp := make(map[string]usedClass)
+	for _, page := range r.pages {
+		for _, class := range page.classes {
+			tmp[class.class] = usedClass{
+				class:     class.class,
+				count:     tmp[class.class].count + class.count,
+				rootCount: tmp[class.class].rootCount + class.rootCount,
+
+				hiddenCount: tmp[class.class].hiddenCount + class.hiddenCount,
+				tags:        append(tmp[class.class].tags, class.tags...),
+			}
+		}
+	}
+
+	out := make([]usedClass, 0, len(tmp))
+	for _, class := range tmp {
+		class.tags = unique(class.tags)
+		slices.Sort(class.tags)
+		out = append(out, class)
+	}
+
+	return out
+}
+
+// pageHook is called for every page that is rendered during a crawl, after
+// the page data has been extracted into result.
+type pageHook func(ctx context.Context, page *rod.Page, result *pageResult) error
+
+func (f *Finder) crawl(ctx context.Context, hooks ...pageHook) (*crawlResult, error) {
+	browser, err := f.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return f.run(ctx, browser, hooks...)
+}
+
+// connect runs the preflight check, if enabled, and connects to the browser
+// that is used for crawling.
+func (f *Finder) connect(ctx context.Context) (*rod.Browser, error) {
+	if f.preflight {
+		if err := f.checkReachable(ctx); err != nil {
+			return nil, &CrawlError{URL: f.rootURL.String(), Stage: StagePreflight, Err: err}
+		}
+	}
+
+	return f.launch(ctx)
+}
+
+// launch launches and connects to the browser and runs the login script, if
+// configured.
+func (f *Finder) launch(ctx context.Context) (*rod.Browser, error) {
+	browser := rod.New().Context(ctx)
+	if f.userDataDir != "" {
+		controlURL, err := launcher.New().Context(ctx).UserDataDir(f.userDataDir).Launch()
+		if err != nil {
+			return nil, &CrawlError{URL: f.rootURL.String(), Stage: StageConnect, Err: fmt.Errorf("launch browser: %w", err)}
+		}
+		browser = browser.ControlURL(controlURL)
+	}
+	if err := browser.Connect(); err != nil {
+		return nil, &CrawlError{URL: f.rootURL.String(), Stage: StageConnect, Err: err}
+	}
+
+	if f.loginScript != nil {
+		if err := f.login(browser); err != nil {
+			browser.Close()
+			return nil, &CrawlError{URL: f.rootURL.String(), Stage: StageLogin, Err: err}
+		}
+	}
+
+	return browser, nil
+}
+
+// login runs the login script on a blank page of the browser. The page is
+// closed afterwards, but the session it established is shared by all pages of
+// the browser. Neither the script nor the pages it visits are logged, because
+// they may contain credentials.
+func (f *Finder) login(browser *rod.Browser) error {
+	page, err := browser.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		return fmt.Errorf("open login page: %w", err)
+	}
+	defer page.Close()
+
+	if err := f.setAcceptLanguage(browser, page); err != nil {
+		return err
+	}
+
+	f.log.Debug("Running login script")
+	if err := f.loginScript(page); err != nil {
+		return fmt.Errorf("run login script: %w", err)
+	}
+
+	return nil
+}
+
+// run crawls the website using the given browser and closes the browser when
+// done. The given hooks are called for every rendered page.
+func (f *Finder) run(ctx context.Context, browser *rod.Browser, hooks ...pageHook) (*crawlResult, error) {
+	var out crawlResult
+	summary, err := f.stream(ctx, browser, hooks, func(outcome pageOutcome) bool {
+		if outcome.err == nil {
+			out.pages = append(out.pages, outcome.result)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	out.summary = summary
+	return &out, nil
+}
+
+// crawlSummary summarizes a finished crawl.
+type crawlSummary struct {
+	visited    int
+	failed     int
+	sampled    int
+	startedAt  time.Time
+	finishedAt time.Time
+
+	// incomplete is the reason why the crawl stopped before all reachable
+	// pages were visited, if it did.
+	incomplete IncompleteReason
+}
+
+// minFailureSample is the minimum number of processed pages before the
+// failure threshold (see [WithFailureThreshold]) is checked, so that a single
+// early failure does not abort the crawl.
+const minFailureSample = 10
+
+// failureRatioExceeds reports whether more than the given ratio of the pages
+// processed so far failed.
+func (s crawlSummary) failureRatioExceeds(ratio float64) bool {
+	total := s.visited + s.failed
+	return total >= minFailureSample && float64(s.failed)/float64(total) > ratio
+}
+
+// merge returns the summary of two crawls that ran side by side. The merged
+// crawl is incomplete if either of the crawls is.
+func (s crawlSummary) merge(other crawlSummary) crawlSummary {
+	if s.startedAt.IsZero() {
+		return other
+	}
+
+	s.visited += other.visited
+	s.failed += other.failed
+	s.sampled += other.sampled
+	if other.startedAt.Before(s.startedAt) {
+		s.startedAt = other.startedAt
+	}
+	if other.finishedAt.After(s.finishedAt) {
+		s.finishedAt = other.finishedAt
+	}
+	if s.incomplete == "" {
+		s.incomplete = other.incomplete
+	}
+
+	return s
+}
+
+// stats returns the summary as [CrawlStats].
+func (s crawlSummary) stats() CrawlStats {
+	stats := CrawlStats{
+		Visited:          s.visited,
+		Failed:           s.failed,
+		SampledPages:     s.sampled,
+		StartedAt:        s.startedAt,
+		Elapsed:          s.finishedAt.Sub(s.startedAt),
+		Incomplete:       s.incomplete != "",
+		IncompleteReason: s.incomplete,
+	}
+	if secs := stats.Elapsed.Seconds(); secs > 0 {
+		stats.PagesPerSecond = float64(s.visited+s.failed) / secs
+	}
+	return stats
+}
+
+// workers returns the number of concurrent workers of a crawl (see
+// [WithConcurrency]).
+func (f *Finder) workers() int {
+	if f.concurrency > 0 {
+		return f.concurrency
+	}
+	return int(math.Min(8, float64(runtime.NumCPU())))
 }
 
-func (f *Finder) findUsed(ctx context.Context) ([]usedClass, error) {
-	browser := rod.New().Context(ctx).MustConnect()
-	defer browser.MustClose()
+// notifyNewClasses calls the new-class callback (see [WithOnNewClass]) for
+// every used class that is not in seen yet, in sorted order, and adds these
+// classes to seen.
+func (f *Finder) notifyNewClasses(seen map[string]bool, classes []usedClass) {
+	if f.onNewClass == nil {
+		return
+	}
+
+	var added []string
+	for _, class := range classes {
+		if class.count > 0 && !seen[class.class] {
+			seen[class.class] = true
+			added = append(added, class.class)
+		}
+	}
+	slices.Sort(added)
+
+	for _, class := range added {
+		f.onNewClass(class)
+	}
+}
+
+// pageOutcome is the outcome of a single page visit.
+type pageOutcome struct {
+	url    string
+	result pageResult
+	err    error
+}
 
-	workers := int(math.Min(8, float64(runtime.NumCPU())))
+// stream crawls the website using the given browser, closes the browser when
+// done, and passes the result of every visited page, or the error of a page
+// that could not be visited, to emit as soon as it is available. If emit
+// returns false, the crawl is stopped. The given hooks are called for every
+// rendered page. If not even the root page could be visited, its error is
+// returned. Otherwise, a summary of the crawl is returned.
+func (f *Finder) stream(ctx context.Context, browser *rod.Browser, hooks []pageHook, emit func(pageOutcome) bool) (crawlSummary, error) {
+	conn := f.newBrowserConn(browser)
+	defer conn.close()
+	defer f.counters.start()()
+
+	summary := crawlSummary{startedAt: time.Now()}
+
+	ctx, crawlSpan := f.startSpan(ctx, "siteperf.crawl", attribute.String("siteperf.root", f.rootURL.String()))
+	defer func() {
+		crawlSpan.SetAttributes(
+			attribute.Int("siteperf.visited", summary.visited),
+			attribute.Int("siteperf.failed", summary.failed),
+		)
+		if summary.incomplete != "" {
+			crawlSpan.SetAttributes(attribute.String("siteperf.incomplete", string(summary.incomplete)))
+		}
+		crawlSpan.End()
+	}()
+
+	if f.maxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, f.maxDuration, errMaxDuration)
+		defer cancel()
+	}
+
+	// Stops the workers and the dispatcher of the frontier when the crawl is
+	// done or emit stops it.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	workers := f.workers()
 	var wg sync.WaitGroup
 	wg.Add(workers)
 
-	visited := visitedPages{paths: make(map[string]bool)}
-	queue := make(chan string)
-	enqueue := func(urls ...*url.URL) {
-		for _, url := range urls {
+	visited := f.newVisitedPages()
+	queue := make(chan crawlTarget)
+	enqueue := func(depth int, urls ...*url.URL) {
+		f.counters.queued.Add(int64(len(urls)))
+		for i, url := range urls {
 			select {
 			case <-ctx.Done():
+				f.counters.queued.Add(-int64(len(urls) - i))
 				return
-			case queue <- url.String():
+			case queue <- crawlTarget{url: url.String(), depth: depth}:
+				f.counters.queued.Add(-1)
+			}
+		}
+	}
+
+	if f.bfs {
+		frontier := newFrontier()
+		enqueue = func(depth int, urls ...*url.URL) {
+			f.counters.queued.Add(int64(len(urls)))
+			targets := make([]crawlTarget, len(urls))
+			for i, url := range urls {
+				targets[i] = crawlTarget{url: url.String(), depth: depth}
 			}
+			frontier.push(targets...)
 		}
+
+		go func() {
+			for {
+				target, ok := frontier.pop(ctx)
+				if !ok {
+					return
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case queue <- target:
+					f.counters.queued.Add(-1)
+				}
+			}
+		}()
 	}
 
-	classChan := make(chan usedClass)
+	shuffle := f.newShuffler()
+
+	robots := f.loadRobots(ctx)
+	throttle := newThrottle(f.effectiveCrawlDelay(robots))
+	hosts := newHostLimiter(f.perHostConcurrency)
+	structures := f.newStructureSet()
+	budget := f.newExtractBudget()
+
+	results := make(chan pageOutcome)
 
 	for i := 0; i < workers; i++ {
 		go func() {
@@ -108,108 +642,431 @@ func (f *Finder) findUsed(ctx context.Context) ([]usedClass, error) {
 				case <-timer.C:
 					timer.Stop()
 					return
-				case pageUrl := <-queue:
+				case target := <-queue:
 					timer.Stop()
+					pageUrl := target.url
 
-					f.log.Debug("Visiting page", "url", pageUrl)
-
-					page := browser.MustPage(pageUrl)
-					cleanup := func() { page.MustClose() }
-
-					if err := page.WaitLoad(); err != nil {
-						cleanup()
-						f.log.Warn("Failed to load page", "url", pageUrl, "err", err)
-						continue
-					}
-
-					if err := page.WaitStable(100 * time.Millisecond); err != nil {
-						cleanup()
-						f.log.Warn("Failed to wait for page stability", "url", pageUrl, "err", err)
-						continue
+					if err := throttle.wait(ctx); err != nil {
+						return
 					}
 
-					pageClasses, err := f.extractClasses(page, pageUrl)
+					release, err := hosts.acquire(ctx, pageUrl)
 					if err != nil {
-						cleanup()
-						f.log.Warn("Failed to extract classes", "url", pageUrl, "err", err)
-						continue
+						return
 					}
-
-					links, err := f.findLinks(page, pageUrl, &visited)
-					if err != nil {
-						cleanup()
-						f.log.Warn("Failed to find links", "url", pageUrl, "err", err)
-						continue
+					pageCtx, pageSpan := f.startSpan(ctx, "siteperf.page",
+						attribute.String("url.full", pageUrl),
+						attribute.Int("siteperf.depth", target.depth),
+					)
+					current := conn.get()
+					result, err := f.visitPage(pageCtx, current, pageUrl, hooks, structures, budget)
+					if err != nil && conn.recover(ctx, current) {
+						result, err = f.visitPage(pageCtx, conn.get(), pageUrl, hooks, structures, budget)
 					}
-
-					go enqueue(links...)
-
-					for _, class := range pageClasses {
+					release()
+					if err == nil {
+						pageSpan.SetAttributes(
+							attribute.Int("siteperf.classes", len(result.classes)),
+							attribute.Bool("siteperf.cached", result.cached),
+						)
+					}
+					endSpan(pageSpan, err)
+					if err != nil {
+						f.counters.failed.Add(1)
+						f.log.Warn("Failed to visit page", "url", pageUrl, "err", err)
 						select {
 						case <-ctx.Done():
-							cleanup()
 							return
-						case classChan <- class:
+						case results <- pageOutcome{url: pageUrl, err: err}:
 						}
+						continue
 					}
+					f.counters.visited.Add(1)
+					result.classes = f.applyClassManifest(result.classes)
+					f.markRedirected(result, visited)
+
+					links := f.unvisited(result.links, target.depth+1, visited, robots)
+					shuffle(links)
+					go enqueue(target.depth+1, links...)
 
-					cleanup()
+					select {
+					case <-ctx.Done():
+						return
+					case results <- pageOutcome{url: pageUrl, result: result}:
+					}
 				}
 			}
 		}()
 	}
 
-	go enqueue(f.rootURL)
+	// The root page is not counted towards the page limit, but links to it
+	// must not crawl it again.
+	root := *f.rootURL
+	if root.Path == "" {
+		root.Path = "/"
+	}
+	visited.alias(f.visitKey(&root))
+	go enqueue(0, f.rootURL)
 
 	go func() {
 		wg.Wait()
-		close(classChan)
+		close(results)
 	}()
 
-	tmp := make(map[string]usedClass)
-	for class := range classChan {
-		tmp[class.class] = usedClass{
-			class: class.class,
-			count: tmp[class.class].count + class.count,
+	var rootErr error
+	seenClasses := make(map[string]bool)
+	names := make(internPool)
+	for outcome := range results {
+		if outcome.err == nil {
+			summary.visited++
+			if outcome.result.sampled {
+				summary.sampled++
+			}
+			names.internClasses(outcome.result.classes)
+			f.notifyNewClasses(seenClasses, outcome.result.classes)
+		} else {
+			summary.failed++
+			if outcome.url == f.rootURL.String() {
+				rootErr = outcome.err
+			}
+		}
+
+		if !emit(outcome) {
+			cancel()
+			for range results {
+			}
+			summary.finishedAt = time.Now()
+			summary.incomplete = IncompleteStopped
+			return summary, nil
+		}
+
+		if f.failureThreshold > 0 && summary.failureRatioExceeds(f.failureThreshold) {
+			cancel()
+			for range results {
+			}
+			summary.finishedAt = time.Now()
+			return summary, fmt.Errorf("%w: %d of %d pages failed", ErrTooManyFailures, summary.failed, summary.visited+summary.failed)
 		}
 	}
+	summary.finishedAt = time.Now()
 
-	out := make([]usedClass, 0, len(tmp))
-	for _, class := range tmp {
-		out = append(out, class)
+	// If not even the root page could be crawled, the result is meaningless
+	// and would report every class as unused.
+	if summary.visited == 0 && rootErr != nil {
+		return summary, rootErr
 	}
 
-	return out, nil
+	switch {
+	case errors.Is(context.Cause(ctx), errMaxDuration):
+		summary.incomplete = IncompleteMaxDuration
+	case ctx.Err() != nil:
+		summary.incomplete = IncompleteCanceled
+	case visited.wasLimited():
+		summary.incomplete = IncompletePageLimit
+	case budget.exceeded():
+		summary.incomplete = IncompleteExtractTimeout
+	}
+
+	return summary, nil
+}
+
+// visitPage returns the result for the page with the given URL. If a cache is
+// configured and the page has not changed since it was cached, the cached
+// result is returned. Otherwise, the page is rendered in the browser. Pages
+// are always rendered if hooks are given, because hooks need a live page.
+func (f *Finder) visitPage(ctx context.Context, browser *rod.Browser, pageUrl string, hooks []pageHook, structures *structureSet, budget *extractBudget) (pageResult, error) {
+	extract := true
+	if len(hooks) == 0 {
+		if !f.modifiedAfter(ctx, pageUrl) {
+			if f.cache != nil {
+				if entry, err := f.cache.load(pageUrl); err == nil {
+					f.log.Debug("Using cached page of unmodified page", "url", pageUrl)
+					result := entry.result()
+					result.cached = true
+					return result, nil
+				}
+			}
+			f.log.Debug("Skipping extraction of unmodified page", "url", pageUrl, "since", f.modifiedSince)
+			extract = false
+		} else if budget.exhausted() {
+			f.log.Debug("Skipping extraction of page after the extraction budget was exhausted", "url", pageUrl)
+			extract = false
+		}
+	}
+
+	if f.cache == nil || len(hooks) > 0 || !extract {
+		return f.renderPage(ctx, browser, pageUrl, hooks, structures, extract)
+	}
+
+	changed := f.changedPage(pageUrl)
+	if f.onlyURLs != nil && !changed {
+		if entry, err := f.cache.load(pageUrl); err == nil {
+			f.log.Debug("Using cached page of unchanged page", "url", pageUrl)
+			result := entry.result()
+			result.cached = true
+			return result, nil
+		}
+	}
+
+	validator, cached, ok := f.cache.lookup(ctx, f.client, f.requestHeader(), f.maxResponseSize, pageUrl)
+	if ok && !changed {
+		f.log.Debug("Using cached page", "url", pageUrl)
+		cached.cached = true
+		return cached, nil
+	}
+
+	result, err := f.renderPage(ctx, browser, pageUrl, nil, structures, true)
+	if err != nil {
+		return result, err
+	}
+
+	// The result of a page with a known structure lacks its classes, and the
+	// results of sampled pages and of pages with meta robots directives
+	// depend on the configuration of the crawl, so they must not be used in
+	// later crawls.
+	if result.duplicate || result.sampled || result.restricted {
+		return result, nil
+	}
+
+	if err := f.cache.store(pageUrl, validator, result); err != nil {
+		f.log.Warn("Failed to cache page", "url", pageUrl, "err", err)
+	}
+
+	return result, nil
+}
+
+// renderPage opens the page with the given URL in the browser and extracts
+// its classes, links, and other data. If extract is false, only the links of
+// the page are extracted. The given hooks are called after the data has been
+// extracted.
+func (f *Finder) renderPage(ctx context.Context, browser *rod.Browser, pageUrl string, hooks []pageHook, structures *structureSet, extract bool) (pageResult, error) {
+	f.log.Debug("Visiting page", "url", pageUrl)
+
+	// To catch all WebSocket messages, network requests, and lifecycle
+	// events, the page must be watched before it navigates to the URL. The
+	// language must be configured before the page is requested, too.
+	watch := f.webSocketQuiet > 0 || f.warc != nil || f.loadEvent.lifecycle() != "" || f.acceptLanguage != ""
+	target := proto.TargetCreateTarget{URL: pageUrl}
+	if watch {
+		target.URL = ""
+	}
+
+	// Ending a span again is a no-op, so the deferred call only ends the span
+	// if the navigation fails.
+	_, navigateSpan := f.startSpan(ctx, "siteperf.navigate")
+	defer navigateSpan.End()
+
+	start := time.Now()
+	releaseNavigation, err := f.acquireNavigation(ctx)
+	if err != nil {
+		return pageResult{}, &CrawlError{URL: pageUrl, Stage: StageOpen, Err: err}
+	}
+	defer releaseNavigation()
+
+	page, err := browser.Page(target)
+	if err != nil {
+		return pageResult{}, &CrawlError{URL: pageUrl, Stage: StageOpen, Err: err}
+	}
+	defer page.Close()
+
+	if err := f.setAcceptLanguage(browser, page); err != nil {
+		return pageResult{}, &CrawlError{URL: pageUrl, Stage: StageOpen, Err: err}
+	}
+
+	var (
+		sockets  *webSocketWatcher
+		recorder *networkRecorder
+	)
+	if f.webSocketQuiet > 0 {
+		sockets = watchWebSockets(page)
+		defer sockets.stop()
+	}
+	if f.warc != nil {
+		recorder = recordNetwork(page)
+		defer recorder.stop()
+	}
+	var waitLifecycle func()
+	if event := f.loadEvent.lifecycle(); event != "" {
+		waitLifecycle = page.WaitNavigation(event)
+	}
+	if watch {
+		if err := page.Navigate(pageUrl); err != nil {
+			return pageResult{}, &CrawlError{URL: pageUrl, Stage: StageOpen, Err: err}
+		}
+	}
+
+	if waitLifecycle != nil {
+		waitLifecycle()
+	} else if err := page.WaitLoad(); err != nil {
+		return pageResult{}, &CrawlError{URL: pageUrl, Stage: StageLoad, Err: err}
+	}
+	releaseNavigation()
+	navigateSpan.End()
+	loadTime := time.Since(start)
+
+	// Error pages render a DOM, too, but their classes must not count as used
+	// across the website.
+	status, err := responseStatus(page)
+	if err != nil {
+		f.log.Warn("Failed to get response status", "url", pageUrl, "err", err)
+	} else if !f.acceptStatus(status) {
+		return pageResult{}, &CrawlError{URL: pageUrl, Stage: StageLoad, Err: &StatusError{StatusCode: status}}
+	}
+
+	if err := page.WaitStable(100 * time.Millisecond); err != nil {
+		return pageResult{}, &CrawlError{URL: pageUrl, Stage: StageLoad, Err: fmt.Errorf("wait for page stability: %w", err)}
+	}
+
+	if sockets != nil {
+		quiet, err := sockets.waitQuiet(ctx, f.webSocketQuiet)
+		if err != nil {
+			return pageResult{}, &CrawlError{URL: pageUrl, Stage: StageLoad, Err: fmt.Errorf("wait for quiet WebSockets: %w", err)}
+		}
+		if !quiet {
+			f.log.Warn("WebSockets did not become quiet", "url", pageUrl, "timeout", maxWebSocketQuietWait)
+		}
+	}
+
+	f.preparePage(page, pageUrl)
+
+	result := pageResult{url: pageUrl, status: status, loadTime: loadTime}
+
+	if info, err := page.Info(); err != nil {
+		f.log.Warn("Failed to get final page URL", "url", pageUrl, "err", err)
+	} else {
+		result.finalURL = info.URL
+	}
+
+	var robots metaRobots
+	if f.respectMetaRobots {
+		if robots, err = readMetaRobots(page); err != nil {
+			f.log.Warn("Failed to read meta robots directives", "url", pageUrl, "err", err)
+		}
+		result.restricted = robots.restricts()
+	}
+
+	if structures != nil && extract && !robots.noindex && len(hooks) == 0 {
+		known, err := structures.add(page)
+		if err != nil {
+			f.log.Warn("Failed to hash page structure", "url", pageUrl, "err", err)
+		}
+		result.duplicate = known
+	}
+
+	switch {
+	case result.duplicate:
+		f.log.Debug("Skipping extraction of page with known structure", "url", pageUrl)
+	case robots.noindex:
+		f.log.Debug("Skipping extraction of noindex page", "url", pageUrl)
+	case extract:
+		_, span := f.startSpan(ctx, "siteperf.extract")
+		err := f.extractPage(page, &result)
+		endSpan(span, err)
+		if err != nil {
+			return pageResult{}, err
+		}
+	}
+
+	if robots.nofollow {
+		f.log.Debug("Not following links of nofollow page", "url", pageUrl)
+	} else if result.links, err = f.findLinks(page, pageUrl); err != nil {
+		return pageResult{}, &CrawlError{URL: pageUrl, Stage: StageLinks, Err: err}
+	}
+
+	if recorder != nil {
+		if err := f.warc.write(recorder.records(page)); err != nil {
+			f.log.Warn("Failed to write WARC records", "url", pageUrl, "err", err)
+		}
+	}
+
+	for _, hook := range hooks {
+		if err := hook(ctx, page, &result); err != nil {
+			f.log.Warn("Failed to run page hook", "url", pageUrl, "err", err)
+		}
+	}
+
+	return result, nil
+}
+
+// changedPage reports whether the page with the given URL is among the changed
+// pages (see [WithOnlyURLs]). Changed pages are matched by their full URL
+// first, and by their path second.
+func (f *Finder) changedPage(pageUrl string) bool {
+	if f.onlyURLs[pageUrl] {
+		return true
+	}
+	if u, err := url.Parse(pageUrl); err == nil {
+		return f.onlyURLs[u.Path]
+	}
+	return false
+}
+
+// effectiveCrawlDelay returns the delay between page visits. An explicitly
+// configured delay takes precedence over the Crawl-delay of robots.txt.
+func (f *Finder) effectiveCrawlDelay(robots *robotsRules) time.Duration {
+	if f.crawlDelay > 0 || robots == nil {
+		return f.crawlDelay
+	}
+	return robots.crawlDelay
 }
 
-func (f *Finder) findLinks(page *rod.Page, pageUrl string, visited *visitedPages) ([]*url.URL, error) {
-	links, err := page.Elements("a[href]")
+// newShuffler returns a function that shuffles discovered links before they
+// are enqueued if random crawl order is enabled. Otherwise, the returned
+// function is a no-op. The returned function is safe for concurrent use.
+func (f *Finder) newShuffler() func([]*url.URL) {
+	if !f.randomOrder {
+		return func([]*url.URL) {}
+	}
+
+	var mux sync.Mutex
+	rng := rand.New(rand.NewSource(f.randomSeed))
+
+	return func(links []*url.URL) {
+		mux.Lock()
+		defer mux.Unlock()
+		rng.Shuffle(len(links), func(i, j int) {
+			links[i], links[j] = links[j], links[i]
+		})
+	}
+}
+
+// findLinks returns the URLs of all links on the page that point to the host
+// of the root URL or one of the allowed hosts.
+func (f *Finder) findLinks(page *rod.Page, pageUrl string) ([]*url.URL, error) {
+	base, err := url.Parse(pageUrl)
+	if err != nil {
+		return nil, fmt.Errorf("parse page URL: %w", err)
+	}
+
+	links, err := extractAttributes(page, "a[href]", "href", "rel")
 	if err != nil {
 		return nil, fmt.Errorf("get links: %w", err)
 	}
 
 	var out []*url.URL
 	for _, link := range links {
-		href, err := link.Attribute("href")
-		if err != nil {
-			f.log.Warn("Failed to get href attribute of link", "err", err)
+		href := link.Attrs["href"]
+
+		if f.respectNofollow && hasNofollow(link.Attrs["rel"]) {
 			continue
 		}
 
-		to, err := url.Parse(deref(href))
+		// Resolve the link against the page URL so that relative and
+		// protocol-relative links ("//example.com/page") get the scheme and
+		// host of the page they were found on.
+		to, err := base.Parse(href)
 		if err != nil {
-			f.log.Warn("Failed to parse link URL", "href", deref(href), "err", err)
+			f.log.Warn("Failed to parse link URL", "href", href, "err", err)
 			continue
 		}
 
-		if to.Host != f.rootURL.Host {
+		if to.Scheme != "http" && to.Scheme != "https" {
 			continue
 		}
 
-		if visited.has(to.Path) || (f.pageLimit > 0 && visited.count() >= f.pageLimit) {
+		if !f.hostAllowed(to.Host) {
 			continue
 		}
-		visited.add(to.Path)
 
 		out = append(out, to)
 	}
@@ -217,38 +1074,272 @@ func (f *Finder) findLinks(page *rod.Page, pageUrl string, visited *visitedPages
 	return out, nil
 }
 
-func (f *Finder) extractClasses(page *rod.Page, pageUrl string) ([]usedClass, error) {
+// hasNofollow reports whether the given rel attribute value contains the
+// "nofollow" keyword.
+func hasNofollow(rel string) bool {
+	return slices.ContainsFunc(strings.Fields(rel), func(keyword string) bool {
+		return strings.EqualFold(keyword, "nofollow")
+	})
+}
+
+// unvisited returns the links that have not been visited yet and are allowed
+// by robots.txt and the [WithShouldCrawl] callback, and marks them as visited.
+// The links are found on a page at depth-1, so they are crawled at the given
+// depth. Once the page limit is reached, no more links are returned.
+func (f *Finder) unvisited(links []*url.URL, depth int, visited *visitedPages, robots *robotsRules) []*url.URL {
+	var out []*url.URL
+	for _, to := range links {
+		to, ok := f.applyFragmentMode(to)
+		if !ok || !robots.allowed(to) {
+			continue
+		}
+		to = f.applyCanonicalQuery(to)
+
+		key := f.visitKey(to)
+		if visited.has(key) {
+			continue
+		}
+		if f.shouldCrawl != nil && !f.shouldCrawl(to, depth) {
+			continue
+		}
+		if f.pageLimit > 0 && visited.count() >= f.pageLimit {
+			visited.markLimited()
+			continue
+		}
+		visited.add(key)
+
+		out = append(out, to)
+	}
+	return out
+}
+
+// applyFragmentMode applies the configured [FragmentMode] to the given link.
+// It returns the link to follow, and false if the link should not be followed
+// at all.
+func (f *Finder) applyFragmentMode(link *url.URL) (*url.URL, bool) {
+	if link.Fragment == "" {
+		return link, true
+	}
+
+	switch f.fragmentMode {
+	case FragmentDistinct:
+		return link, true
+	case FragmentSkip:
+		return nil, false
+	default:
+		stripped := *link
+		stripped.Fragment = ""
+		stripped.RawFragment = ""
+		return &stripped, true
+	}
+}
+
+// markRedirected marks the final URL of a page that was redirected, e.g. from
+// http to https or to the www subdomain, as visited, so that links to the
+// final URL do not crawl the same page again.
+func (f *Finder) markRedirected(result pageResult, visited *visitedPages) {
+	if result.finalURL == "" || result.finalURL == result.url {
+		return
+	}
+	final, err := url.Parse(result.finalURL)
+	if err != nil {
+		return
+	}
+	if final, ok := f.applyFragmentMode(final); ok {
+		visited.alias(f.visitKey(f.applyCanonicalQuery(final)))
+	}
+}
+
+// applyCanonicalQuery replaces the query of the link with its canonical form
+// (see [WithCanonicalizeQuery]). Without a canonicalization function, the
+// link is returned unchanged.
+func (f *Finder) applyCanonicalQuery(link *url.URL) *url.URL {
+	if f.canonicalizeQuery == nil {
+		return link
+	}
+	canonical := *link
+	canonical.RawQuery = f.canonicalizeQuery(link.Query()).Encode()
+	return &canonical
+}
+
+// visitKey returns the key under which the given link is tracked in the map
+// of visited pages. In [FragmentDistinct] mode, the fragment is part of the
+// key; otherwise, only the path is. If queries are canonicalized (see
+// [WithCanonicalizeQuery]), the query is part of the key, too. Links to other
+// hosts than the host of the root URL (see [WithAllowedHosts]) are prefixed
+// with their host.
+func (f *Finder) visitKey(link *url.URL) string {
+	key := link.Path
+	if f.canonicalizeQuery != nil && link.RawQuery != "" {
+		key += "?" + link.RawQuery
+	}
+	if f.fragmentMode == FragmentDistinct && link.Fragment != "" {
+		key += "#" + link.Fragment
+	}
+	if link.Host != f.rootURL.Host {
+		key = "//" + link.Host + key
+	}
+	return key
+}
+
+// extractClasses returns the used classes of the page. If elements are
+// sampled (see [WithElementSample]), it also reports whether the classes are
+// based on a sample of the elements of the page.
+func (f *Finder) extractClasses(page *rod.Page, pageUrl string) ([]usedClass, bool, error) {
 	found := make(map[string]int)
+	foundOnRoot := make(map[string]int)
+	foundHidden := make(map[string]int)
+	foundOnTags := make(map[string][]string)
 
-	elements, err := page.Elements("[class]")
+	var sampled bool
+	extract := func(page *rod.Page) ([]elementAttributes, error) {
+		elements, total, err := queryAttributes(page, f.classSelector(), []string{"class"}, f.ignoreHidden, f.elementSample)
+		if total > len(elements) {
+			sampled = true
+		}
+		return elements, err
+	}
+
+	elements, err := extract(page)
 	if err != nil {
-		return nil, fmt.Errorf("get elements with class attribute: %w", err)
+		return nil, false, fmt.Errorf("get elements with class attribute: %w", err)
 	}
 
-	for _, el := range elements {
-		rawClass, err := el.Attribute("class")
+	// Only the <html> and <body> elements of the page itself are root
+	// elements, not those of its frames.
+	roots := len(elements)
+
+	if f.includeFrames {
+		frames, err := f.sameOriginFrames(page, pageUrl)
 		if err != nil {
-			f.log.Warn("Failed to get class attribute of element", "url", pageUrl, "err", err)
-			continue
+			f.log.Warn("Failed to find frames", "url", pageUrl, "err", err)
 		}
+		for _, frame := range frames {
+			frameElements, err := extract(frame)
+			if err != nil {
+				f.log.Warn("Failed to get elements with class attribute of frame", "url", pageUrl, "err", err)
+				continue
+			}
+			elements = append(elements, frameElements...)
+		}
+	}
 
-		classList := strings.Split(deref(rawClass), " ")
-		classList = filter(classList, func(s string) bool { return strings.TrimSpace(s) != "" })
-
-		for _, class := range classList {
+	for i, el := range elements {
+		root := i < roots && (el.Tag == "html" || el.Tag == "body")
+		for _, class := range f.classTokens(el.Attrs["class"]) {
+			if el.Hidden {
+				foundHidden[class]++
+				continue
+			}
 			found[class]++
+			if !slices.Contains(foundOnTags[class], el.Tag) {
+				foundOnTags[class] = append(foundOnTags[class], el.Tag)
+			}
+			if root {
+				foundOnRoot[class]++
+			}
 		}
 	}
 
 	var out []usedClass
 	for class, count := range found {
+		tags := foundOnTags[class]
+		slices.Sort(tags)
 		out = append(out, usedClass{
-			class: class,
-			count: count,
+			class:       class,
+			count:       count,
+			rootCount:   foundOnRoot[class],
+			hiddenCount: foundHidden[class],
+			tags:        tags,
 		})
 	}
+	for class, count := range foundHidden {
+		if found[class] == 0 {
+			out = append(out, usedClass{class: class, hiddenCount: count})
+		}
+	}
 
-	return out, nil
+	return out, sampled, nil
+}
+
+// extractPage extracts the classes, stylesheets, and other data of the page
+// into result.
+func (f *Finder) extractPage(page *rod.Page, result *pageResult) error {
+	pageUrl := result.url
+
+	var err error
+	if result.classes, result.sampled, err = f.extractClasses(page, pageUrl); err != nil {
+		return &CrawlError{URL: pageUrl, Stage: StageExtract, Err: err}
+	}
+
+	if len(f.scriptMarkupTypes) > 0 {
+		scriptClasses, err := f.extractScriptMarkupClasses(page)
+		if err != nil {
+			f.log.Warn("Failed to extract classes from script markup", "url", pageUrl, "err", err)
+		}
+		result.classes = mergeUsedClasses(result.classes, scriptClasses)
+	}
+
+	if f.svgUseReferences {
+		svgClasses, err := f.extractSVGUseClasses(page)
+		if err != nil {
+			f.log.Warn("Failed to extract SVG <use> references", "url", pageUrl, "err", err)
+		}
+		result.classes = mergeUsedClasses(result.classes, svgClasses)
+	}
+
+	if result.stylesheets, err = f.findStylesheets(page, pageUrl); err != nil {
+		f.log.Warn("Failed to find stylesheets", "url", pageUrl, "err", err)
+	}
+
+	if f.scanInlineStyles {
+		if result.inlineStyles, err = f.extractInlineStyles(page); err != nil {
+			f.log.Warn("Failed to extract inline styles", "url", pageUrl, "err", err)
+		}
+	}
+
+	if len(f.attributeSelectors) > 0 {
+		if result.attributes, err = f.countAttributeSelectors(page); err != nil {
+			f.log.Warn("Failed to count attribute selectors", "url", pageUrl, "err", err)
+		}
+	}
+
+	return nil
+}
+
+// splitClassList splits the value of a class attribute into its tokens. Tokens
+// are separated by any whitespace, including tabs, line breaks, and
+// non-breaking spaces from malformed markup, so that " btn" and "btn" are
+// counted as the same class.
+func splitClassList(raw string) []string {
+	return strings.Fields(raw)
+}
+
+// classTokens returns the classes of the given class attribute value. If
+// invalid class tokens are dropped (see [WithDropInvalidClasses]), tokens that
+// are not valid class names are omitted. The classes of the AMP runtime are
+// omitted if they are excluded (see [WithExcludeAMPClasses]).
+func (f *Finder) classTokens(raw string) []string {
+	tokens := splitClassList(raw)
+	if len(f.ampPrefixes) > 0 {
+		tokens = filter(tokens, func(token string) bool {
+			return !f.isAMPClass(token)
+		})
+	}
+	if !f.dropInvalidClasses {
+		return tokens
+	}
+	return filter(tokens, isValidClassToken)
+}
+
+// isValidClassToken reports whether the class token is a valid class name or
+// a variant of one, like "md:hover:flex".
+func isValidClassToken(token string) bool {
+	if isValidClass(token) {
+		return true
+	}
+	variants, base := SplitVariants(token)
+	return len(variants) > 0 && isValidClass(base)
 }
 
 func filter[S ~[]E, E any](s S, fn func(E) bool) S {
@@ -264,33 +1355,84 @@ func filter[S ~[]E, E any](s S, fn func(E) bool) S {
 	return filtered
 }
 
-func deref[V any](v *V) V {
-	if v == nil || v == (*V)(nil) {
-		var zero V
-		return zero
-	}
-	return *v
-}
-
 type visitedPages struct {
 	sync.RWMutex
 	paths map[string]bool
+
+	// aliases contains the final paths of redirected pages.
+	aliases map[string]bool
+
+	// bloom replaces paths and aliases if visited pages are tracked by a
+	// bloom filter (see [WithBloomVisited]), in which case n is the number
+	// of added paths.
+	bloom *bloomFilter
+	n     int
+
+	// limited reports whether links were skipped because the page limit was
+	// reached.
+	limited bool
+}
+
+// newVisitedPages returns the set of visited pages of a crawl, which is backed
+// by a bloom filter if configured (see [WithBloomVisited]).
+func (f *Finder) newVisitedPages() *visitedPages {
+	if f.bloomVisited > 0 {
+		return &visitedPages{bloom: newBloomFilter(f.bloomVisited, f.bloomFPRate)}
+	}
+	return &visitedPages{paths: make(map[string]bool), aliases: make(map[string]bool)}
+}
+
+func (vp *visitedPages) markLimited() {
+	vp.Lock()
+	defer vp.Unlock()
+	vp.limited = true
+}
+
+func (vp *visitedPages) wasLimited() bool {
+	vp.RLock()
+	defer vp.RUnlock()
+	return vp.limited
 }
 
 func (vp *visitedPages) add(path string) {
 	vp.Lock()
 	defer vp.Unlock()
+	if vp.bloom != nil {
+		vp.bloom.add(path)
+		vp.n++
+		return
+	}
 	vp.paths[path] = true
 }
 
+// alias marks the path as visited without counting it towards the page
+// limit, because it is reached by a redirect from a page that was counted.
+func (vp *visitedPages) alias(path string) {
+	vp.Lock()
+	defer vp.Unlock()
+	if vp.bloom != nil {
+		vp.bloom.add(path)
+		return
+	}
+	if !vp.paths[path] {
+		vp.aliases[path] = true
+	}
+}
+
 func (vp *visitedPages) has(path string) bool {
 	vp.RLock()
 	defer vp.RUnlock()
-	return vp.paths[path]
+	if vp.bloom != nil {
+		return vp.bloom.has(path)
+	}
+	return vp.paths[path] || vp.aliases[path]
 }
 
 func (vp *visitedPages) count() int {
 	vp.RLock()
 	defer vp.RUnlock()
+	if vp.bloom != nil {
+		return vp.n
+	}
 	return len(vp.paths)
 }
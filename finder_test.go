@@ -0,0 +1,281 @@
+package siteperf
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod/lib/launcher"
+)
+
+// requireBrowser skips the test if no Chrome or Chromium is installed. The
+// crawls of the tests would otherwise download a browser first.
+func requireBrowser(tb testing.TB) {
+	tb.Helper()
+	if _, ok := launcher.LookPath(); !ok {
+		tb.Skip("no Chrome or Chromium found")
+	}
+}
+
+// serveFixture serves the files of the given directory within testdata. If
+// latency is positive, every response is delayed by it, which simulates the
+// round trip to a remote website.
+func serveFixture(tb testing.TB, dir string, latency time.Duration) *httptest.Server {
+	tb.Helper()
+	files := http.FileServer(http.Dir("testdata/" + dir))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if latency > 0 {
+			time.Sleep(latency)
+		}
+		files.ServeHTTP(w, r)
+	}))
+	tb.Cleanup(srv.Close)
+	return srv
+}
+
+// fixtureUsed is the class usage of the pages of the "site" fixture.
+var fixtureUsed = map[string]int{
+	"page": 6, "page--home": 1,
+	"header": 6, "nav": 6, "nav__link": 18, "nav__link--active": 6,
+	"container": 6, "footer": 6, "footer__text": 6,
+	"hero": 1, "hero__title": 1,
+	"btn": 5, "btn--primary": 1, "btn--secondary": 1, "btn--link": 3,
+	"grid": 1, "card": 3, "card--featured": 1, "card__title": 3,
+	"prose": 4, "prose__title": 4, "prose__lead": 4,
+	"post-list": 1, "post-list__item": 3, "post-list__link": 3,
+	"post": 3, "post__title": 3, "code": 3, "code__block": 3,
+}
+
+func TestFinder_FindUsed(t *testing.T) {
+	requireBrowser(t)
+	srv := serveFixture(t, "site", 0)
+
+	for _, workers := range []int{1, 4} {
+		t.Run(fmt.Sprintf("concurrency=%d", workers), func(t *testing.T) {
+			f, err := New(srv.URL, 0, WithConcurrency(workers))
+			if err != nil {
+				t.Fatalf("New() failed: %v", err)
+			}
+
+			used, err := f.FindUsed(context.Background())
+			if err != nil {
+				t.Fatalf("FindUsed() failed: %v", err)
+			}
+			if !maps.Equal(used, fixtureUsed) {
+				t.Errorf("FindUsed() = %v, want %v", used, fixtureUsed)
+			}
+		})
+	}
+}
+
+// BenchmarkFindUsed crawls the "site" fixture with different numbers of
+// workers. The responses are delayed to simulate a remote website, so that
+// the benchmark shows how well the crawl overlaps the visits of the pages.
+func BenchmarkFindUsed(b *testing.B) {
+	requireBrowser(b)
+	srv := serveFixture(b, "site", 50*time.Millisecond)
+
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("concurrency=%d", workers), func(b *testing.B) {
+			f, err := New(srv.URL, 0, WithConcurrency(workers))
+			if err != nil {
+				b.Fatalf("New() failed: %v", err)
+			}
+
+			var pages int
+			for i := 0; i < b.N; i++ {
+				_, stats, err := f.FindUnusedWithStats(context.Background(), nil)
+				if err != nil {
+					b.Fatalf("FindUnusedWithStats() failed: %v", err)
+				}
+				pages += stats.Visited
+			}
+			b.ReportMetric(float64(pages)/b.Elapsed().Seconds(), "pages/s")
+		})
+	}
+}
+
+// serveRedirectingRoot serves a site whose root redirects to /home, and whose
+// pages link to both the root and the final URL of the root.
+func serveRedirectingRoot(tb testing.TB) *httptest.Server {
+	tb.Helper()
+	page := func(class string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `<!DOCTYPE html><html><body class=%q>
+				<a href="/">Root</a> <a href="/home">Home</a> <a href="/other">Other</a>
+			</body></html>`, class)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/{$}", http.RedirectHandler("/home", http.StatusFound))
+	mux.Handle("/home", page("home"))
+	mux.Handle("/other", page("other"))
+
+	srv := httptest.NewServer(mux)
+	tb.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFinder_markRedirected(t *testing.T) {
+	f, err := New("https://example.com/", 0)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	visited := f.newVisitedPages()
+	visited.alias(f.visitKey(f.rootURL))
+	f.markRedirected(pageResult{url: "https://example.com/", finalURL: "https://example.com/home"}, visited)
+
+	var links []*url.URL
+	for _, link := range []string{"https://example.com/", "https://example.com/home", "https://example.com/other"} {
+		u, _ := url.Parse(link)
+		links = append(links, u)
+	}
+	var got []string
+	for _, link := range f.unvisited(links, 1, visited, nil) {
+		got = append(got, link.String())
+	}
+
+	if want := []string{"https://example.com/other"}; !slices.Equal(got, want) {
+		t.Errorf("unvisited() = %q, want %q", got, want)
+	}
+}
+
+func TestFinder_Pages_redirectedRoot(t *testing.T) {
+	requireBrowser(t)
+	srv := serveRedirectingRoot(t)
+
+	f, err := New(srv.URL, 0)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	visits := make(map[string]int)
+	var root PageResult
+	for page, err := range f.Pages(context.Background()) {
+		if err != nil {
+			t.Fatalf("Pages() failed: %v", err)
+		}
+		if page.URL == srv.URL {
+			root = page
+		}
+		visits[page.URL]++
+		if page.FinalURL != "" {
+			visits[page.FinalURL]++
+		}
+	}
+
+	if want := srv.URL + "/home"; root.FinalURL != want {
+		t.Errorf("final URL of the root is %q, want %q", root.FinalURL, want)
+	}
+	if root.Classes["home"] != 1 {
+		t.Errorf("classes of the root are %v, want the classes of /home", root.Classes)
+	}
+	if n := visits[srv.URL+"/home"]; n != 1 {
+		t.Errorf("/home was visited %d times, want 1", n)
+	}
+	if n := visits[srv.URL+"/other"]; n != 1 {
+		t.Errorf("/other was visited %d times, want 1", n)
+	}
+}
+
+func TestSplitClassList(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want []string
+	}{
+		{name: "single space", raw: "btn card", want: []string{"btn", "card"}},
+		{name: "leading and trailing spaces", raw: " btn  card ", want: []string{"btn", "card"}},
+		{name: "repeated spaces", raw: "btn     card", want: []string{"btn", "card"}},
+		{name: "tabs", raw: "\tbtn\t\tcard\t", want: []string{"btn", "card"}},
+		{name: "line breaks", raw: "btn\ncard\r\nlink", want: []string{"btn", "card", "link"}},
+		{name: "form feed", raw: "btn\fcard", want: []string{"btn", "card"}},
+		{name: "non-breaking space", raw: "btn\u00a0card", want: []string{"btn", "card"}},
+		{name: "mixed", raw: " \t btn \n\f card  ", want: []string{"btn", "card"}},
+		{name: "only whitespace", raw: " \t\n ", want: []string{}},
+		{name: "empty", raw: "", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitClassList(tt.raw); !slices.Equal(got, tt.want) {
+				t.Errorf("splitClassList(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithClassManifest_whitespace(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest map[string]string
+		want     map[string][]string
+	}{
+		{
+			name:     "trimmed names",
+			manifest: map[string]string{"btn": "a1b2", "card": "c3d4"},
+			want:     map[string][]string{"a1b2": {"btn"}, "c3d4": {"card"}},
+		},
+		{
+			name:     "spaces around names",
+			manifest: map[string]string{" btn ": "  a1b2", "card\t": "c3d4\n"},
+			want:     map[string][]string{"a1b2": {"btn"}, "c3d4": {"card"}},
+		},
+		{
+			name:     "same name with different whitespace",
+			manifest: map[string]string{"btn": "a1b2", " btn": "a1b2 "},
+			want:     map[string][]string{"a1b2": {"btn"}},
+		},
+		{
+			name:     "empty names",
+			manifest: map[string]string{" ": "a1b2", "card": "\t"},
+			want:     map[string][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := New("https://example.com", 0, WithClassManifest(tt.manifest))
+			if err != nil {
+				t.Fatalf("New() failed: %v", err)
+			}
+			if !maps.EqualFunc(f.classManifest, tt.want, slices.Equal) {
+				t.Errorf("class manifest = %q, want %q", f.classManifest, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithPageLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		pageLimit int
+		opts      []Option
+		want      int
+	}{
+		{name: "limit of New", pageLimit: 10, want: 10},
+		{name: "overrides limit of New", pageLimit: 10, opts: []Option{WithPageLimit(50)}, want: 50},
+		{name: "removes limit of New", pageLimit: 10, opts: []Option{WithPageLimit(0)}, want: 0},
+		{name: "negative limit", opts: []Option{WithPageLimit(-1)}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := New("https://example.com", tt.pageLimit, tt.opts...)
+			if err != nil {
+				t.Fatalf("New() failed: %v", err)
+			}
+			if f.pageLimit != tt.want {
+				t.Errorf("page limit is %d, want %d", f.pageLimit, tt.want)
+			}
+		})
+	}
+}
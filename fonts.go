@@ -0,0 +1,152 @@
+package siteperf
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// FontFace is a font declared by an @font-face rule.
+type FontFace struct {
+	// Family is the unquoted font family name declared by the rule.
+	Family string `json:"family"`
+
+	// Sources contains the URLs referenced by the "src" descriptor.
+	Sources []string `json:"sources,omitempty"`
+
+	// Weight and Style are the values of the "font-weight" and "font-style"
+	// descriptors, if present.
+	Weight string `json:"weight,omitempty"`
+	Style  string `json:"style,omitempty"`
+
+	// Line is the 1-based line number of the @font-face rule.
+	Line int `json:"line"`
+}
+
+// ExtractFontFaces parses the provided CSS and returns the fonts declared by
+// its @font-face rules, in the order in which they appear. Rules without a
+// font-family descriptor are ignored.
+func ExtractFontFaces(css string) ([]FontFace, error) {
+	sheet := parseStylesheet(css)
+
+	var out []FontFace
+	for _, rule := range sheet.atRules {
+		if rule.name != "font-face" {
+			continue
+		}
+
+		face := FontFace{Line: rule.line}
+		for _, decl := range parseDeclarations(rule.block) {
+			switch decl.property {
+			case "font-family":
+				face.Family = unquote(decl.value)
+			case "src":
+				face.Sources = cssURLs(decl.value)
+			case "font-weight":
+				face.Weight = decl.value
+			case "font-style":
+				face.Style = decl.value
+			}
+		}
+
+		if face.Family != "" {
+			out = append(out, face)
+		}
+	}
+
+	return out, nil
+}
+
+// FindUnusedFontFaces parses the provided CSS and returns the fonts declared
+// by its @font-face rules whose family is not referenced by any "font-family"
+// or "font" declaration of a style rule within the same CSS. The font files of
+// the returned fonts are candidates for removal. Family names are compared
+// case-insensitively.
+func FindUnusedFontFaces(css string) ([]FontFace, error) {
+	faces, err := ExtractFontFaces(css)
+	if err != nil {
+		return nil, err
+	}
+
+	referenced := make(map[string]bool)
+	for _, rule := range parseStylesheet(css).rules {
+		for _, decl := range rule.declarations {
+			switch decl.property {
+			case "font-family":
+				for _, family := range fontFamilies(decl.value) {
+					referenced[strings.ToLower(family)] = true
+				}
+			case "font":
+				for _, family := range fontShorthandFamilies(decl.value) {
+					referenced[strings.ToLower(family)] = true
+				}
+			}
+		}
+	}
+
+	return filter(faces, func(face FontFace) bool {
+		return !referenced[strings.ToLower(face.Family)]
+	}), nil
+}
+
+// fontFamilies returns the unquoted family names of a font-family value.
+func fontFamilies(value string) []string {
+	var out []string
+	for _, family := range splitTopLevel(value, ',') {
+		if family = unquote(family); family != "" {
+			out = append(out, family)
+		}
+	}
+	return out
+}
+
+// fontShorthandFamilies returns the family names of a "font" shorthand value,
+// like "italic bold 12px/1.5 Georgia, serif". The family list follows the
+// font size, which is the last token of the first comma-separated part that
+// contains a digit or is a size keyword.
+func fontShorthandFamilies(value string) []string {
+	parts := splitTopLevel(value, ',')
+	if len(parts) == 0 {
+		return nil
+	}
+
+	tokens := strings.Fields(parts[0])
+	sizeIndex := slices.IndexFunc(tokens, isFontSizeToken)
+	if sizeIndex < 0 {
+		// System font keywords like "caption" or "menu".
+		return nil
+	}
+	first := strings.Join(tokens[sizeIndex+1:], " ")
+
+	return fontFamilies(strings.Join(append([]string{first}, parts[1:]...), ","))
+}
+
+var fontSizeKeywords = map[string]bool{
+	"xx-small": true, "x-small": true, "small": true, "medium": true,
+	"large": true, "x-large": true, "xx-large": true, "xxx-large": true,
+	"larger": true, "smaller": true,
+}
+
+func isFontSizeToken(token string) bool {
+	token = strings.ToLower(token)
+	if size, _, ok := strings.Cut(token, "/"); ok {
+		token = size
+	}
+	return strings.ContainsAny(token, "0123456789") || fontSizeKeywords[token]
+}
+
+var cssURLRE = regexp.MustCompile(`url\(\s*(?:"([^"]*)"|'([^']*)'|([^)\s]*))\s*\)`)
+
+// cssURLs returns the URLs referenced by url() functions within a CSS value.
+func cssURLs(value string) []string {
+	var out []string
+	for _, match := range cssURLRE.FindAllStringSubmatch(value, -1) {
+		for _, u := range match[1:] {
+			if u != "" {
+				out = append(out, u)
+				break
+			}
+		}
+	}
+	return out
+}
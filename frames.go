@@ -0,0 +1,57 @@
+package siteperf
+
+import (
+	"fmt"
+
+	"github.com/go-rod/rod"
+)
+
+// maxFrameDepth is the maximum nesting depth of the frames that are searched
+// for classes (see [WithIncludeFrames]).
+const maxFrameDepth = 5
+
+// sameOriginFrames returns the frames of the same-origin <iframe> elements of
+// the page, including the frames nested within them, up to maxFrameDepth
+// levels deep. Cross-origin frames cannot be accessed from the page and are
+// skipped.
+func (f *Finder) sameOriginFrames(page *rod.Page, pageUrl string) ([]*rod.Page, error) {
+	return f.appendFrames(nil, page, pageUrl, 1)
+}
+
+func (f *Finder) appendFrames(frames []*rod.Page, page *rod.Page, pageUrl string, depth int) ([]*rod.Page, error) {
+	if depth > maxFrameDepth {
+		return frames, nil
+	}
+
+	iframes, err := page.Elements("iframe")
+	if err != nil {
+		return frames, fmt.Errorf("get <iframe> elements: %w", err)
+	}
+
+	for _, iframe := range iframes {
+		// contentDocument is null for cross-origin frames.
+		res, err := iframe.Eval(`() => ({ src: this.src, sameOrigin: this.contentDocument !== null })`)
+		if err != nil {
+			f.log.Warn("Failed to inspect frame", "url", pageUrl, "err", err)
+			continue
+		}
+		src := res.Value.Get("src").Str()
+		if !res.Value.Get("sameOrigin").Bool() {
+			f.log.Info("Skipping cross-origin frame", "url", pageUrl, "frame", src)
+			continue
+		}
+
+		frame, err := iframe.Frame()
+		if err != nil {
+			f.log.Warn("Failed to access frame", "url", pageUrl, "frame", src, "err", err)
+			continue
+		}
+
+		frames = append(frames, frame)
+		if frames, err = f.appendFrames(frames, frame, pageUrl, depth+1); err != nil {
+			f.log.Warn("Failed to find nested frames", "url", pageUrl, "frame", src, "err", err)
+		}
+	}
+
+	return frames, nil
+}
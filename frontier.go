@@ -0,0 +1,89 @@
+package siteperf
+
+import (
+	"context"
+	"sync"
+)
+
+// crawlTarget is a page that is waiting to be visited.
+type crawlTarget struct {
+	url string
+
+	// depth is the number of links between the root page and the page. The
+	// root page has a depth of 0.
+	depth int
+}
+
+// frontier is an unbounded queue of crawl targets that is ordered by depth.
+// Targets of the same depth are popped in the order they were pushed, which
+// results in a breadth-first crawl. A frontier is safe for concurrent use.
+type frontier struct {
+	mux    sync.Mutex
+	levels [][]crawlTarget
+	ready  chan struct{}
+}
+
+func newFrontier() *frontier {
+	return &frontier{ready: make(chan struct{}, 1)}
+}
+
+// push adds the given targets to the frontier.
+func (fr *frontier) push(targets ...crawlTarget) {
+	fr.mux.Lock()
+	for _, t := range targets {
+		for len(fr.levels) <= t.depth {
+			fr.levels = append(fr.levels, nil)
+		}
+		fr.levels[t.depth] = append(fr.levels[t.depth], t)
+	}
+	fr.mux.Unlock()
+
+	select {
+	case fr.ready <- struct{}{}:
+	default:
+	}
+}
+
+// pop removes and returns the shallowest target of the frontier. It blocks
+// until a target is available or the context is canceled, in which case false
+// is returned.
+func (fr *frontier) pop(ctx context.Context) (crawlTarget, bool) {
+	for {
+		if t, ok := fr.tryPop(); ok {
+			return t, true
+		}
+
+		select {
+		case <-ctx.Done():
+			return crawlTarget{}, false
+		case <-fr.ready:
+		}
+	}
+}
+
+func (fr *frontier) tryPop() (crawlTarget, bool) {
+	fr.mux.Lock()
+	defer fr.mux.Unlock()
+
+	for depth, level := range fr.levels {
+		if len(level) == 0 {
+			continue
+		}
+		t := level[0]
+		fr.levels[depth] = level[1:]
+		if len(fr.levels[depth]) == 0 {
+			// Drop the backing array so that popped targets can be freed.
+			fr.levels[depth] = nil
+		}
+		// Keep the signal for the remaining targets.
+		if len(fr.levels[depth]) > 0 || depth+1 < len(fr.levels) {
+			select {
+			case fr.ready <- struct{}{}:
+			default:
+			}
+		}
+		return t, true
+	}
+
+	return crawlTarget{}, false
+}
@@ -0,0 +1,28 @@
+package siteperf
+
+import (
+	"slices"
+	"strings"
+)
+
+// GroupByPrefix groups the given class names by their first segment when
+// split on "-", e.g. "text-xs" and "text-sm" are grouped under "text", and
+// "bg-red" under "bg". Class names without a "-" form a group of their own.
+// The class names within each group are sorted. This makes long lists of
+// utility classes easier to digest, because whole families of classes become
+// visible.
+func GroupByPrefix(classes []string) map[string][]string {
+	groups := make(map[string][]string)
+	for _, class := range classes {
+		prefix, _, _ := strings.Cut(class, "-")
+		if prefix == "" {
+			// Classes like "-mt-2" that start with a "-".
+			prefix = "-"
+		}
+		groups[prefix] = append(groups[prefix], class)
+	}
+	for _, group := range groups {
+		slices.Sort(group)
+	}
+	return groups
+}
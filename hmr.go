@@ -0,0 +1,64 @@
+package siteperf
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// maxWebSocketQuietWait is the maximum time to wait for a quiet period of the
+// WebSocket connections of a page, so that a page that never stops sending
+// messages does not block the crawl.
+const maxWebSocketQuietWait = 30 * time.Second
+
+// webSocketWatcher records the time of the last WebSocket message that was
+// received by a page.
+type webSocketWatcher struct {
+	last atomic.Int64
+	stop func()
+}
+
+// watchWebSockets starts recording the WebSocket messages received by the
+// page. The returned watcher must be stopped when the page is no longer used.
+func watchWebSockets(page *rod.Page) *webSocketWatcher {
+	page, cancel := page.WithCancel()
+	w := &webSocketWatcher{stop: cancel}
+	wait := page.EachEvent(func(e *proto.NetworkWebSocketFrameReceived) {
+		w.last.Store(time.Now().UnixNano())
+	})
+	go wait()
+	return w
+}
+
+// waitQuiet waits until no WebSocket message has been received for the given
+// duration. If the page has not received any messages, waitQuiet returns
+// immediately. It reports false if the page did not become quiet within
+// maxWebSocketQuietWait.
+func (w *webSocketWatcher) waitQuiet(ctx context.Context, quiet time.Duration) (bool, error) {
+	deadline := time.Now().Add(maxWebSocketQuietWait)
+	for {
+		last := w.last.Load()
+		if last == 0 {
+			return true, nil
+		}
+
+		remaining := quiet - time.Since(time.Unix(0, last))
+		if remaining <= 0 {
+			return true, nil
+		}
+		if time.Now().Add(remaining).After(deadline) {
+			return false, nil
+		}
+
+		timer := time.NewTimer(remaining)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return false, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
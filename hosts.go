@@ -0,0 +1,61 @@
+package siteperf
+
+import (
+	"context"
+	"net/url"
+	"sync"
+)
+
+// hostAllowed reports whether links to the given host are followed. Links to
+// the host of the root URL are always followed.
+func (f *Finder) hostAllowed(host string) bool {
+	return host == f.rootURL.Host || f.allowedHosts[host]
+}
+
+// hostLimiter limits the number of concurrent page visits per host. A
+// hostLimiter is safe for concurrent use.
+type hostLimiter struct {
+	limit int
+
+	mux  sync.Mutex
+	sems map[string]chan struct{}
+}
+
+// newHostLimiter returns a limiter that allows the given number of concurrent
+// page visits per host. A limit of 0 or less returns nil, which does not
+// limit anything.
+func newHostLimiter(limit int) *hostLimiter {
+	if limit <= 0 {
+		return nil
+	}
+	return &hostLimiter{limit: limit, sems: make(map[string]chan struct{})}
+}
+
+// acquire blocks until a visit of the page with the given URL is allowed, or
+// the context is canceled. The returned function must be called when the
+// visit is done.
+func (l *hostLimiter) acquire(ctx context.Context, pageUrl string) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	var host string
+	if u, err := url.Parse(pageUrl); err == nil {
+		host = u.Host
+	}
+
+	l.mux.Lock()
+	sem, ok := l.sems[host]
+	if !ok {
+		sem = make(chan struct{}, l.limit)
+		l.sems[host] = sem
+	}
+	l.mux.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	}
+}
@@ -0,0 +1,60 @@
+package siteperf
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// FindUnusedInHTML returns the classes defined by the given CSS (see
+// [ExtractClasses]) that are not used by any element of the given HTML, sorted
+// by name. It neither crawls nor renders anything, which makes it a fast and
+// hermetic way to check the rendered markup of components in tests. Because
+// the markup is not rendered, classes that would be added by scripts are not
+// found.
+func FindUnusedInHTML(markup, css string) ([]string, error) {
+	defined, err := ExtractClasses(css)
+	if err != nil {
+		return nil, err
+	}
+
+	used, err := htmlClassCounts(strings.NewReader(markup))
+	if err != nil {
+		return nil, err
+	}
+
+	return filter(defined, func(class string) bool {
+		return used[class] == 0
+	}), nil
+}
+
+// htmlClassCounts returns the classes of the elements of the given HTML,
+// mapped to the number of elements they were found on.
+func htmlClassCounts(r io.Reader) (map[string]int, error) {
+	counts := make(map[string]int)
+
+	z := html.NewTokenizer(r)
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if err := z.Err(); !errors.Is(err, io.EOF) {
+				return nil, err
+			}
+			return counts, nil
+		case html.StartTagToken, html.SelfClosingTagToken:
+			for {
+				key, val, more := z.TagAttr()
+				if string(key) == "class" {
+					for _, class := range unique(splitClassList(string(val))) {
+						counts[class]++
+					}
+				}
+				if !more {
+					break
+				}
+			}
+		}
+	}
+}
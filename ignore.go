@@ -0,0 +1,79 @@
+package siteperf
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// ignoredClasses matches the classes that are never reported as unused (see
+// [WithIgnoreClasses]).
+type ignoredClasses struct {
+	names    map[string]bool
+	patterns []*regexp.Regexp
+}
+
+func newIgnoredClasses(patterns []string) *ignoredClasses {
+	ignored := &ignoredClasses{names: make(map[string]bool)}
+	for _, pattern := range patterns {
+		if !strings.ContainsAny(pattern, "*?") {
+			ignored.names[pattern] = true
+			continue
+		}
+		expr := regexp.QuoteMeta(pattern)
+		expr = strings.ReplaceAll(expr, `\*`, ".*")
+		expr = strings.ReplaceAll(expr, `\?`, ".")
+		ignored.patterns = append(ignored.patterns, regexp.MustCompile("^"+expr+"$"))
+	}
+	return ignored
+}
+
+// match reports whether the class is ignored. A nil *ignoredClasses ignores no
+// class.
+func (ic *ignoredClasses) match(class string) bool {
+	if ic == nil {
+		return false
+	}
+	if ic.names[class] {
+		return true
+	}
+	for _, re := range ic.patterns {
+		if re.MatchString(class) {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutIgnored returns the classes that are not ignored (see
+// [WithIgnoreClasses]).
+func (f *Finder) withoutIgnored(classes []string) []string {
+	if f.ignoredClasses == nil {
+		return classes
+	}
+	return filter(classes, func(class string) bool {
+		return !f.ignoredClasses.match(class)
+	})
+}
+
+// ParseIgnoreFile reads the classes and class patterns of an ignore file, like
+// a .siteperfignore file, for use with [WithIgnoreClasses]. The file contains
+// one class name or pattern per line. Empty lines and lines that start with
+// "#" are skipped, and a leading "." is removed, so that a list of classes
+// written as selectors can be used as is.
+func ParseIgnoreFile(r io.Reader) ([]string, error) {
+	var patterns []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, strings.TrimPrefix(line, "."))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return patterns, nil
+}
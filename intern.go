@@ -0,0 +1,34 @@
+package siteperf
+
+// internPool stores a single copy of every distinct string that is added to
+// it. The classes of every crawled page are interned, so that a class that is
+// used on thousands of pages is kept in memory once instead of once per page.
+// Interning does not reduce the number of allocations, because the names are
+// already allocated when the results of the pages are decoded, but the copies
+// of the pages are garbage collected instead of being kept alive until the
+// crawl is done (see BenchmarkInternClasses).
+//
+// An internPool is not safe for concurrent use. It is used by the collector
+// of a crawl, which receives the results of all workers.
+type internPool map[string]string
+
+// intern returns the pooled copy of s, adding s to the pool if it is not
+// pooled yet.
+func (p internPool) intern(s string) string {
+	if pooled, ok := p[s]; ok {
+		return pooled
+	}
+	p[s] = s
+	return s
+}
+
+// internClasses replaces the names and tags of the classes with their pooled
+// copies.
+func (p internPool) internClasses(classes []usedClass) {
+	for i := range classes {
+		classes[i].class = p.intern(classes[i].class)
+		for j, tag := range classes[i].tags {
+			classes[i].tags[j] = p.intern(tag)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package siteperf
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// decodedPages returns the classes of the given number of pages, each with
+// their own copy of the class names and tags, like the classes that are
+// decoded from the results of the page evaluations. The pages share a
+// vocabulary of 500 classes.
+func decodedPages(pages int) [][]usedClass {
+	out := make([][]usedClass, pages)
+	for p := range out {
+		classes := make([]usedClass, 200)
+		for i := range classes {
+			classes[i] = usedClass{
+				class: fmt.Sprintf("component__element--modifier-%d", (p*7+i)%500),
+				count: 1,
+				tags:  []string{strings.Clone("div")},
+			}
+		}
+		out[p] = classes
+	}
+	return out
+}
+
+// BenchmarkInternClasses compares the memory that the collector of a crawl
+// keeps alive for the classes of 1,000 pages with and without interning. The
+// names are already allocated when they are decoded, so interning does not
+// reduce the allocations, but the memory retained after the crawl, which is
+// reported as retained-B/op.
+func BenchmarkInternClasses(b *testing.B) {
+	for _, intern := range []bool{false, true} {
+		b.Run(fmt.Sprintf("intern=%t", intern), func(b *testing.B) {
+			b.ReportAllocs()
+
+			var retained uint64
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				before := heapAlloc()
+				b.StartTimer()
+
+				pages := decodedPages(1000)
+				if intern {
+					names := make(internPool)
+					for _, classes := range pages {
+						names.internClasses(classes)
+					}
+				}
+
+				b.StopTimer()
+				after := heapAlloc()
+				retained += after - min(before, after)
+				runtime.KeepAlive(pages)
+				b.StartTimer()
+			}
+			b.ReportMetric(float64(retained)/float64(b.N), "retained-B/op")
+		})
+	}
+}
+
+// heapAlloc returns the bytes of the live heap objects after a garbage
+// collection.
+func heapAlloc() uint64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
@@ -4,13 +4,18 @@ import (
 	"context"
 	"log/slog"
 	"sync"
+	"time"
 
 	"github.com/dusted-go/logging/prettylog"
 )
 
+// DefaultTimeFormat is the default layout of the timestamps of log records.
+const DefaultTimeFormat = "[15:04:05.000]"
+
 var (
-	mux      sync.RWMutex
-	logLevel = slog.LevelInfo
+	mux        sync.RWMutex
+	logLevel   = slog.LevelInfo
+	timeFormat = DefaultTimeFormat
 )
 
 func SetLevel(level slog.Level) {
@@ -25,6 +30,22 @@ func GetLevel() slog.Level {
 	return logLevel
 }
 
+// SetTimeFormat sets the layout of the timestamps of log records, e.g.
+// time.RFC3339. An empty layout omits timestamps entirely. Unlike the log
+// level, the time format also applies to loggers that have already been
+// created.
+func SetTimeFormat(layout string) {
+	mux.Lock()
+	defer mux.Unlock()
+	timeFormat = layout
+}
+
+func GetTimeFormat() string {
+	mux.RLock()
+	defer mux.RUnlock()
+	return timeFormat
+}
+
 func Debug() func() {
 	prev := GetLevel()
 	SetLevel(slog.LevelDebug)
@@ -34,13 +55,38 @@ func Debug() func() {
 type handler struct {
 	slog.Handler
 	prefix string
+
+	// mux guards time, which holds the time of the record that is currently
+	// being handled, so that replaceTime can format it.
+	mux  *sync.Mutex
+	time *time.Time
 }
 
 func (h *handler) Handle(ctx context.Context, record slog.Record) error {
 	record.Message = h.prefix + record.Message
+
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	*h.time = record.Time
+
 	return h.Handler.Handle(ctx, record)
 }
 
+// replaceTime replaces the pre-formatted timestamp of the pretty handler with
+// the record time formatted using the configured time format.
+func (h *handler) replaceTime(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 || a.Key != slog.TimeKey {
+		return a
+	}
+
+	layout := GetTimeFormat()
+	if layout == "" {
+		return slog.Attr{}
+	}
+
+	return slog.String(slog.TimeKey, h.time.Format(layout))
+}
+
 func New(name string) *slog.Logger {
 	mux.RLock()
 	defer mux.RUnlock()
@@ -50,12 +96,16 @@ func New(name string) *slog.Logger {
 		prefix = "[" + name + "] "
 	}
 
-	return slog.New(&handler{
-		Handler: prettylog.NewHandler(&slog.HandlerOptions{
-			Level:       logLevel,
-			AddSource:   false,
-			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr { return a },
-		}),
+	h := &handler{
 		prefix: prefix,
+		mux:    &sync.Mutex{},
+		time:   &time.Time{},
+	}
+	h.Handler = prettylog.NewHandler(&slog.HandlerOptions{
+		Level:       logLevel,
+		AddSource:   false,
+		ReplaceAttr: h.replaceTime,
 	})
+
+	return slog.New(h)
 }
@@ -0,0 +1,108 @@
+package siteperf
+
+import (
+	"slices"
+	"strings"
+)
+
+// animationKeywords are the keywords of the "animation" shorthand that are not
+// animation names.
+var animationKeywords = map[string]bool{
+	"none": true, "initial": true, "inherit": true, "unset": true, "revert": true, "revert-layer": true,
+	"auto": true, "infinite": true,
+	"linear": true, "ease": true, "ease-in": true, "ease-out": true, "ease-in-out": true,
+	"step-start": true, "step-end": true,
+	"normal": true, "reverse": true, "alternate": true, "alternate-reverse": true,
+	"forwards": true, "backwards": true, "both": true,
+	"running": true, "paused": true,
+}
+
+// ExtractKeyframes parses the provided CSS and returns the names of the
+// animations that are defined by its @keyframes rules, including the vendor
+// prefixed ones like @-webkit-keyframes, and the names of the animations that
+// are referenced by the "animation" and "animation-name" declarations of its
+// style rules. Both lists are sorted and free of duplicates. Animation names
+// are case-sensitive.
+func ExtractKeyframes(css string) (defined, referenced []string, err error) {
+	sheet := parseStylesheet(css)
+
+	for _, rule := range sheet.atRules {
+		if rule.name == "keyframes" || strings.HasSuffix(rule.name, "-keyframes") {
+			if name := unquote(rule.prelude); name != "" {
+				defined = append(defined, name)
+			}
+		}
+	}
+
+	for _, rule := range sheet.rules {
+		for _, decl := range rule.declarations {
+			switch unprefixed(decl.property) {
+			case "animation-name":
+				for _, name := range splitTopLevel(decl.value, ',') {
+					if name = unquote(name); !animationKeywords[name] {
+						referenced = append(referenced, name)
+					}
+				}
+			case "animation":
+				referenced = append(referenced, animationShorthandNames(decl.value)...)
+			}
+		}
+	}
+
+	slices.Sort(defined)
+	slices.Sort(referenced)
+
+	return slices.Compact(defined), slices.Compact(referenced), nil
+}
+
+// FindUnusedKeyframes parses the provided CSS and returns the sorted names of
+// the animations that are defined by @keyframes rules, but not referenced by
+// any "animation" or "animation-name" declaration within the same CSS.
+// Animations that are only referenced by scripts (e.g. the Web Animations API)
+// or inline styles are reported as well.
+func FindUnusedKeyframes(css string) ([]string, error) {
+	defined, referenced, err := ExtractKeyframes(css)
+	if err != nil {
+		return nil, err
+	}
+
+	return filter(defined, func(name string) bool {
+		_, found := slices.BinarySearch(referenced, name)
+		return !found
+	}), nil
+}
+
+// animationShorthandNames returns the animation names of the value of an
+// "animation" declaration. Every comma-separated animation contributes the
+// first token that is neither a keyword, a number, a time, nor a function.
+func animationShorthandNames(value string) []string {
+	var names []string
+	for _, animation := range splitTopLevel(value, ',') {
+		for _, token := range splitTopLevel(strings.Join(strings.Fields(animation), " "), ' ') {
+			if animationKeywords[token] || strings.Contains(token, "(") || isNumericToken(token) {
+				continue
+			}
+			names = append(names, unquote(token))
+			break
+		}
+	}
+	return names
+}
+
+// isNumericToken reports whether the token is a number, like an iteration
+// count, or a dimension, like a duration of "1.5s" or a delay of "-200ms".
+func isNumericToken(token string) bool {
+	token = strings.TrimLeft(token, "+-")
+	return token != "" && (token[0] == '.' || token[0] >= '0' && token[0] <= '9')
+}
+
+// unprefixed returns the property without its vendor prefix, e.g. "animation"
+// for "-webkit-animation".
+func unprefixed(property string) string {
+	for _, prefix := range []string{"-webkit-", "-moz-", "-ms-", "-o-"} {
+		if rest, ok := strings.CutPrefix(property, prefix); ok {
+			return rest
+		}
+	}
+	return property
+}
@@ -0,0 +1,237 @@
+package siteperf
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// LinkGraph is the graph of links between the pages of a crawl.
+type LinkGraph struct {
+	// Links maps the URL of every crawled page to the sorted URLs of the
+	// pages it links to. Only links that point to the host of the root URL or
+	// one of the allowed hosts (see [WithAllowedHosts]) are included.
+	Links map[string][]string `json:"links"`
+
+	// Cycles contains the strongly connected components of the graph that
+	// consist of more than one page, i.e. groups of pages that can all be
+	// reached from each other. Each cycle is sorted, and the cycles are
+	// sorted by their first page. It is only populated if cycles are
+	// detected (see [DetectCycles]).
+	Cycles [][]string `json:"cycles,omitempty"`
+}
+
+// LinkGraphOption is an option for [Finder.LinkGraph].
+type LinkGraphOption func(*linkGraphOptions)
+
+type linkGraphOptions struct {
+	excludeSelfLoops bool
+	detectCycles     bool
+}
+
+// ExcludeSelfLoops removes the links of pages to themselves from the graph.
+func ExcludeSelfLoops() LinkGraphOption {
+	return func(o *linkGraphOptions) {
+		o.excludeSelfLoops = true
+	}
+}
+
+// DetectCycles populates the Cycles of the graph with its strongly connected
+// components, which reveals navigation cycles.
+func DetectCycles() LinkGraphOption {
+	return func(o *linkGraphOptions) {
+		o.detectCycles = true
+	}
+}
+
+// LinkGraph crawls the website of the Finder and returns the graph of links
+// between the crawled pages.
+func (f *Finder) LinkGraph(ctx context.Context, opts ...LinkGraphOption) (LinkGraph, error) {
+	var options linkGraphOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	result, err := f.crawl(ctx)
+	if err != nil {
+		return LinkGraph{}, fmt.Errorf("crawl: %w", err)
+	}
+
+	g := f.linkGraph(result)
+	if options.excludeSelfLoops {
+		g = g.WithoutSelfLoops()
+	}
+	if options.detectCycles {
+		g.Cycles = g.StronglyConnectedComponents()
+	}
+
+	return g, nil
+}
+
+// WithoutSelfLoops returns a copy of the graph without the links of pages to
+// themselves.
+func (g LinkGraph) WithoutSelfLoops() LinkGraph {
+	out := LinkGraph{Links: make(map[string][]string, len(g.Links)), Cycles: g.Cycles}
+	for page, links := range g.Links {
+		out.Links[page] = filter(links, func(link string) bool {
+			return link != page
+		})
+	}
+	return out
+}
+
+// StronglyConnectedComponents returns the strongly connected components of
+// the graph that consist of more than one page. Each component is sorted,
+// and the components are sorted by their first page.
+func (g LinkGraph) StronglyConnectedComponents() [][]string {
+	// Tarjan's algorithm.
+	var (
+		index   = make(map[string]int)
+		lowlink = make(map[string]int)
+		onStack = make(map[string]bool)
+		stack   []string
+		out     [][]string
+		visit   func(page string)
+	)
+
+	visit = func(page string) {
+		index[page] = len(index)
+		lowlink[page] = index[page]
+		stack = append(stack, page)
+		onStack[page] = true
+
+		for _, link := range g.Links[page] {
+			if _, ok := index[link]; !ok {
+				visit(link)
+				lowlink[page] = min(lowlink[page], lowlink[link])
+			} else if onStack[link] {
+				lowlink[page] = min(lowlink[page], index[link])
+			}
+		}
+
+		if lowlink[page] != index[page] {
+			return
+		}
+
+		var component []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == page {
+				break
+			}
+		}
+		if len(component) > 1 {
+			slices.Sort(component)
+			out = append(out, component)
+		}
+	}
+
+	pages := make([]string, 0, len(g.Links))
+	for page := range g.Links {
+		pages = append(pages, page)
+	}
+	slices.Sort(pages)
+
+	for _, page := range pages {
+		if _, ok := index[page]; !ok {
+			visit(page)
+		}
+	}
+
+	slices.SortFunc(out, func(a, b []string) int {
+		return strings.Compare(a[0], b[0])
+	})
+
+	return out
+}
+
+func (f *Finder) linkGraph(result *crawlResult) LinkGraph {
+	g := LinkGraph{Links: make(map[string][]string, len(result.pages))}
+	for _, page := range result.pages {
+		links := make([]string, 0, len(page.links))
+		for _, link := range page.links {
+			if link, ok := f.applyFragmentMode(link); ok {
+				links = append(links, link.String())
+			}
+		}
+		links = unique(links)
+		slices.Sort(links)
+		g.Links[page.url] = links
+	}
+	return g
+}
+
+// Pages returns the sorted URLs of all pages of the graph, i.e. the crawled
+// pages and the pages they link to.
+func (g LinkGraph) Pages() []string {
+	var out []string
+	for page, links := range g.Links {
+		out = append(out, page)
+		out = append(out, links...)
+	}
+	out = unique(out)
+	slices.Sort(out)
+	return out
+}
+
+// OrphanPages reports the pages that are listed in the sitemap at the given
+// URL, but cannot be reached by following links from the root URL. Such
+// pages usually indicate a gap in the navigation of the website. If
+// sitemapURL is empty, the sitemap is fetched from "/sitemap.xml" of the
+// root URL. Sitemap URLs on hosts that are not crawled are ignored.
+//
+// A page is considered reachable if it was crawled or is linked from a
+// crawled page, so pages that are only missing because of a page limit or
+// robots.txt are not reported. The returned URLs are sorted.
+func (f *Finder) OrphanPages(ctx context.Context, sitemapURL string) ([]string, error) {
+	listed, err := f.SitemapURLs(ctx, sitemapURL)
+	if err != nil {
+		return nil, err
+	}
+
+	graph, err := f.LinkGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.orphans(listed, graph), nil
+}
+
+// orphans returns the listed URLs that are not pages of the graph. URLs are
+// compared by their visit key, so that links that only differ in their
+// fragment (depending on the fragment mode) or query are considered equal.
+func (f *Finder) orphans(listed []string, graph LinkGraph) []string {
+	reachable := make(map[string]bool)
+	for _, page := range graph.Pages() {
+		if u, err := url.Parse(page); err == nil {
+			reachable[f.orphanKey(u)] = true
+		}
+	}
+
+	out := make([]string, 0)
+	for _, page := range listed {
+		u, err := f.rootURL.Parse(page)
+		if err != nil || !f.hostAllowed(u.Host) {
+			continue
+		}
+		if u, ok := f.applyFragmentMode(u); ok && !reachable[f.orphanKey(u)] {
+			out = append(out, page)
+		}
+	}
+
+	return out
+}
+
+// orphanKey returns the visit key of the given URL, treating an empty path
+// as "/" so that "https://example.com" matches "https://example.com/".
+func (f *Finder) orphanKey(u *url.URL) string {
+	if u.Path == "" {
+		u = &url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/", Fragment: u.Fragment}
+	}
+	return f.visitKey(u)
+}
@@ -0,0 +1,32 @@
+package siteperf
+
+import (
+	"fmt"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// setAcceptLanguage configures the page to request the configured language
+// (see [WithAcceptLanguage]). It must be called before the page navigates.
+// The user agent of the browser is kept, because Chrome only allows to
+// override the language together with the user agent.
+func (f *Finder) setAcceptLanguage(browser *rod.Browser, page *rod.Page) error {
+	if f.acceptLanguage == "" {
+		return nil
+	}
+
+	version, err := proto.BrowserGetVersion{}.Call(browser)
+	if err != nil {
+		return fmt.Errorf("get user agent: %w", err)
+	}
+
+	if err := page.SetUserAgent(&proto.NetworkSetUserAgentOverride{
+		UserAgent:      version.UserAgent,
+		AcceptLanguage: f.acceptLanguage,
+	}); err != nil {
+		return fmt.Errorf("set accept language: %w", err)
+	}
+
+	return nil
+}
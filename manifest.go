@@ -0,0 +1,39 @@
+package siteperf
+
+// applyClassManifest renames the used classes of a page from the names that
+// are found in the DOM to the source names of the configured class manifest
+// (see [WithClassManifest]). Classes that are not in the manifest keep their
+// name. If multiple source names map to the same DOM name, the usage is
+// attributed to each of them.
+func (f *Finder) applyClassManifest(classes []usedClass) []usedClass {
+	if len(f.classManifest) == 0 {
+		return classes
+	}
+
+	merged := make(map[string]usedClass, len(classes))
+	var order []string
+	for _, uc := range classes {
+		names, ok := f.classManifest[uc.class]
+		if !ok {
+			names = []string{uc.class}
+		}
+		for _, name := range names {
+			m, ok := merged[name]
+			if !ok {
+				order = append(order, name)
+			}
+			merged[name] = usedClass{
+				class:       name,
+				count:       m.count + uc.count,
+				rootCount:   m.rootCount + uc.rootCount,
+				hiddenCount: m.hiddenCount + uc.hiddenCount,
+			}
+		}
+	}
+
+	out := make([]usedClass, 0, len(order))
+	for _, name := range order {
+		out = append(out, merged[name])
+	}
+	return out
+}
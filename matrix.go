@@ -0,0 +1,50 @@
+package siteperf
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// UsageMatrix crawls the website of the Finder and returns a page×class matrix
+// of class usage: counts[i][j] is the number of elements of pages[i] that have
+// classes[j]. Pages and classes are sorted, and every class was found on at
+// least one of the pages. The matrix allows to visualize which pages share
+// which classes, e.g. as a heatmap, or to cluster pages by the components they
+// use.
+func (f *Finder) UsageMatrix(ctx context.Context) (pages []string, classes []string, counts [][]int, err error) {
+	result, err := f.crawl(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("find used classes: %w", err)
+	}
+
+	pageResults := slices.Clone(result.pages)
+	slices.SortFunc(pageResults, func(a, b pageResult) int {
+		return strings.Compare(a.url, b.url)
+	})
+
+	for class := range result.usedCounts() {
+		classes = append(classes, class)
+	}
+	slices.Sort(classes)
+
+	column := make(map[string]int, len(classes))
+	for j, class := range classes {
+		column[class] = j
+	}
+
+	pages = make([]string, len(pageResults))
+	counts = make([][]int, len(pageResults))
+	for i, page := range pageResults {
+		pages[i] = page.url
+		counts[i] = make([]int, len(classes))
+		for _, class := range page.classes {
+			if class.count > 0 {
+				counts[i][column[class.class]] += class.count
+			}
+		}
+	}
+
+	return pages, classes, counts, nil
+}
@@ -0,0 +1,54 @@
+package siteperf
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/go-rod/rod"
+)
+
+// metaRobots contains the directives of the <meta name="robots"> elements of a
+// page (see [WithRespectMetaRobots]).
+type metaRobots struct {
+	noindex  bool
+	nofollow bool
+}
+
+// restricts reports whether any directive restricts the crawl of the page.
+func (r metaRobots) restricts() bool {
+	return r.noindex || r.nofollow
+}
+
+// readMetaRobots returns the directives of the <meta name="robots"> elements
+// of the page. The "none" directive is equivalent to "noindex, nofollow".
+func readMetaRobots(page *rod.Page) (metaRobots, error) {
+	res, err := page.Eval(`() => Array.from(document.querySelectorAll("meta[name][content]"))
+		.filter((el) => el.getAttribute("name").trim().toLowerCase() === "robots")
+		.map((el) => el.getAttribute("content"))`)
+	if err != nil {
+		return metaRobots{}, fmt.Errorf("get <meta name=\"robots\"> elements: %w", err)
+	}
+
+	var contents []string
+	if err := json.Unmarshal([]byte(res.Value.JSON("", "")), &contents); err != nil {
+		return metaRobots{}, fmt.Errorf("decode <meta name=\"robots\"> elements: %w", err)
+	}
+
+	var out metaRobots
+	for _, content := range contents {
+		for _, directive := range strings.Split(content, ",") {
+			switch strings.ToLower(strings.TrimSpace(directive)) {
+			case "noindex":
+				out.noindex = true
+			case "nofollow":
+				out.nofollow = true
+			case "none":
+				out.noindex = true
+				out.nofollow = true
+			}
+		}
+	}
+
+	return out, nil
+}
@@ -0,0 +1,89 @@
+package siteperf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// maxParallelAudits is the maximum number of websites that are crawled at the
+// same time by [AuditMulti]. Every crawl runs its own browser.
+const maxParallelAudits = 4
+
+// MultiAudit is the result of auditing the same CSS against multiple websites
+// (see [AuditMulti]).
+type MultiAudit struct {
+	// Audit is the audit of the union of all websites: a class is only unused
+	// if it is unused on every website, and the usage of a class is the sum
+	// of its usage on all websites.
+	Audit Audit `json:"audit"`
+
+	// Roots maps the root URL of every website to the audit of that website
+	// alone, which allows to compare the usage of the websites.
+	Roots map[string]Audit `json:"roots"`
+}
+
+// AuditMulti crawls the websites of the given root URLs and audits the classes
+// of the given CSS against all of them, e.g. a shared design system that is
+// used by multiple sites. Up to maxParallelAudits websites are crawled at the
+// same time, each by its own [Finder] that is configured with the given
+// options. The crawls have no page limit, unless one is configured using
+// [WithPageLimit]; use [WithMaxDuration] to bound their duration instead. If any crawl fails, the errors of all failed crawls are returned.
+func AuditMulti(ctx context.Context, roots []string, css string, opts ...Option) (MultiAudit, error) {
+	classes, err := ExtractClasses(css)
+	if err != nil {
+		return MultiAudit{}, fmt.Errorf("extract classes: %w", err)
+	}
+
+	finders := make([]*Finder, len(roots))
+	for i, root := range roots {
+		if finders[i], err = New(root, 0, opts...); err != nil {
+			return MultiAudit{}, fmt.Errorf("invalid root URL %q: %w", root, err)
+		}
+	}
+
+	var (
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, maxParallelAudits)
+		results = make([]*crawlResult, len(roots))
+		errs    = make([]error, len(roots))
+	)
+	for i, f := range finders {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case <-ctx.Done():
+				errs[i] = fmt.Errorf("audit %s: %w", roots[i], ctx.Err())
+				return
+			case sem <- struct{}{}:
+			}
+			defer func() { <-sem }()
+
+			if results[i], errs[i] = f.crawl(ctx); errs[i] != nil {
+				errs[i] = fmt.Errorf("audit %s: %w", roots[i], errs[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return MultiAudit{}, err
+	}
+
+	out := MultiAudit{Roots: make(map[string]Audit, len(roots))}
+	union := &crawlResult{}
+	for i, result := range results {
+		out.Roots[roots[i]] = finders[i].auditResult(classes, result)
+		union.pages = append(union.pages, result.pages...)
+		union.summary = union.summary.merge(result.summary)
+	}
+
+	if len(finders) > 0 {
+		out.Audit = finders[0].auditResult(classes, union)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,30 @@
+package siteperf
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+func TestAuditMulti_pageLimit(t *testing.T) {
+	requireBrowser(t)
+	roots := []string{serveFixture(t, "site", 0).URL, serveFixture(t, "site", 0).URL}
+
+	css, err := os.ReadFile("testdata/site/style.css")
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+
+	const limit = 2
+	audit, err := AuditMulti(context.Background(), roots, string(css), WithPageLimit(limit))
+	if err != nil {
+		t.Fatalf("AuditMulti() failed: %v", err)
+	}
+
+	for _, root := range roots {
+		// The root page is not counted towards the page limit.
+		if visited := audit.Roots[root].Crawl.Visited; visited > limit+1 {
+			t.Errorf("%s: %d pages were visited, want at most %d", root, visited, limit+1)
+		}
+	}
+}
@@ -0,0 +1,781 @@
+package siteperf
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Option is a function that configures a [Finder]. Options are passed to [New]
+// and applied in order.
+type Option func(*Finder)
+
+// WithScanInlineStyles configures whether the contents of inline <style>
+// elements are scanned for class definitions. If enabled, [Finder.DefinedClasses]
+// includes the classes defined by the inline styles of all crawled pages, in
+// addition to the classes of the linked stylesheets. This is useful for pages
+// that ship critical CSS, component styles, or the output of CSS-in-JS
+// libraries inline.
+func WithScanInlineStyles(scan bool) Option {
+	return func(f *Finder) {
+		f.scanInlineStyles = scan
+	}
+}
+
+// WithPageWeights assigns an importance weight to individual pages. The keys
+// of the map are either full page URLs or URL paths (e.g. "/pricing"). Pages
+// without a weight have a weight of 1. The weights are used to compute the
+// weighted usage score of each class, which is reported by [Finder.Audit] and
+// used by [Finder.FindUnusedWeighted].
+func WithPageWeights(weights map[string]float64) Option {
+	return func(f *Finder) {
+		f.pageWeights = weights
+	}
+}
+
+// WithRandomOrder shuffles the links discovered on each page before they are
+// enqueued, using a pseudo-random generator seeded with the given seed. When
+// the crawl is limited to a number of pages, this gives a more representative
+// sample of a large website than always crawling the same "first" pages. The
+// shuffle is deterministic for a given seed, but because pages are crawled
+// concurrently, the overall visiting order may still vary between runs.
+func WithRandomOrder(seed int64) Option {
+	return func(f *Finder) {
+		f.randomOrder = true
+		f.randomSeed = seed
+	}
+}
+
+// WithPreflight configures whether the reachability of the root URL is checked
+// before the browser is launched. If enabled, a crawl fails early with a clear
+// error if the root URL cannot be reached or responds with a status code
+// other than 2xx or 3xx, instead of attempting a full crawl that reports every
+// class as unused.
+func WithPreflight(preflight bool) Option {
+	return func(f *Finder) {
+		f.preflight = preflight
+	}
+}
+
+// WithAttributeSelectors configures attribute selectors whose presence is
+// checked on every crawled page. Use [ExtractAttributeSelectors] to get the
+// attribute selectors of a stylesheet. [Finder.Audit] reports how many
+// elements match each selector and which selectors match no element at all,
+// so that styling that is driven by attributes like aria-expanded or
+// data-state can be audited alongside classes.
+func WithAttributeSelectors(selectors []AttributeSelector) Option {
+	return func(f *Finder) {
+		f.attributeSelectors = selectors
+	}
+}
+
+// WithCache enables caching of the results of rendered pages in the given
+// directory. Before a page is rendered, a conditional GET request (using the
+// ETag and Last-Modified headers of the cached response) checks whether the
+// page has changed since it was cached. If the server reports that the page is
+// unchanged, or the content hash of the response matches the cached one, the
+// cached result is used and the page is not rendered in the browser. On a
+// mostly static website, this considerably speeds up repeated crawls.
+//
+// Results are cached per configuration of the extraction, so crawls with
+// options that change the extracted data, like [WithRoleFilter] or
+// [WithIgnoreHidden], do not use each other's results.
+//
+// Note that only the HTML of the page itself is validated. Changes to scripts
+// that alter the rendered DOM are not detected.
+func WithCache(dir string) Option {
+	return func(f *Finder) {
+		f.cache = &pageCache{dir: dir}
+	}
+}
+
+// WithIncludeNonScreen configures whether [Finder.AuditDetailed] reports
+// classes that are only referenced within @media rules for non-screen media
+// (like "print") as unused. By default, such classes are excluded from the
+// unused classes because they never appear in the DOM rendered on screen.
+func WithIncludeNonScreen(include bool) Option {
+	return func(f *Finder) {
+		f.includeNonScreen = include
+	}
+}
+
+// WithRespectNofollow configures whether links with rel="nofollow" are
+// skipped. If enabled, such links are not followed, which avoids crawling
+// pages that the website marks as low-value for crawlers.
+func WithRespectNofollow(respect bool) Option {
+	return func(f *Finder) {
+		f.respectNofollow = respect
+	}
+}
+
+// FragmentMode defines how links with a fragment identifier (e.g.
+// "/docs#install") are handled during a crawl.
+type FragmentMode int
+
+const (
+	// FragmentStrip removes the fragment from links before they are followed,
+	// so that "/docs#install" and "/docs#usage" are both crawled as "/docs",
+	// and only once. This is the default mode and suits classic websites
+	// where fragments are anchors within a document.
+	FragmentStrip = FragmentMode(iota)
+
+	// FragmentDistinct treats links that only differ by their fragment as
+	// distinct pages, so that "/app#/users" and "/app#/settings" are crawled
+	// separately. This mode is meant for single-page applications that use
+	// hash-based routing. Fragments are part of the keys of visited pages, so
+	// the page limit counts each fragment route as its own page.
+	FragmentDistinct
+
+	// FragmentSkip does not follow links with a fragment at all, treating them
+	// as anchors within a document that is reachable through other links.
+	// Links without a fragment are followed as usual.
+	FragmentSkip
+)
+
+// WithFragmentMode configures how links with a fragment identifier are
+// handled. See [FragmentStrip], [FragmentDistinct], and [FragmentSkip]. The
+// default is [FragmentStrip].
+func WithFragmentMode(mode FragmentMode) Option {
+	return func(f *Finder) {
+		f.fragmentMode = mode
+	}
+}
+
+// WithScriptMarkup configures <script> types (e.g. "application/json" or
+// "text/x-template") whose contents are scanned for embedded markup. Some
+// websites ship server-rendered component markup within such scripts, which
+// is only inserted into the DOM by JavaScript after the crawl has read the
+// page. The classes of all class attributes found within these scripts are
+// counted as used. If a script contains JSON, its string values are scanned.
+//
+// The scan is a heuristic that matches class attributes textually, without
+// actually parsing the markup, and may over-count classes.
+func WithScriptMarkup(types ...string) Option {
+	return func(f *Finder) {
+		f.scriptMarkupTypes = nil
+		for _, typ := range types {
+			f.scriptMarkupTypes = append(f.scriptMarkupTypes, strings.ToLower(strings.TrimSpace(typ)))
+		}
+	}
+}
+
+// WithMaxResponseSize limits the size of response bodies that are fetched
+// directly over HTTP, outside of the browser, like stylesheets and the
+// conditional requests of the page cache. Reading a larger body fails with
+// [ErrResponseTooLarge], which protects against servers that stream huge or
+// endless responses. A size of 0 or less disables the limit, which is the
+// default.
+func WithMaxResponseSize(bytes int64) Option {
+	return func(f *Finder) {
+		f.maxResponseSize = bytes
+	}
+}
+
+// WithRespectRobots configures whether the robots.txt file of the root URL is
+// respected. If enabled, links to pages that are disallowed for the "siteperf"
+// user agent (or "*") are not followed, and a Crawl-delay directive is used
+// as the crawl delay unless one is configured explicitly using
+// [WithCrawlDelay]. If robots.txt cannot be fetched, everything is crawled.
+func WithRespectRobots(respect bool) Option {
+	return func(f *Finder) {
+		f.respectRobots = respect
+	}
+}
+
+// WithCrawlDelay configures the minimum delay between two page visits across
+// all concurrent workers. An explicitly configured delay always takes
+// precedence over the Crawl-delay directive of robots.txt (see
+// [WithRespectRobots]), even if it is shorter. A delay of 0 disables the
+// explicit delay, so that the directive of robots.txt applies, if any.
+func WithCrawlDelay(delay time.Duration) Option {
+	return func(f *Finder) {
+		f.crawlDelay = delay
+	}
+}
+
+// WithIgnoreHidden configures whether elements within hidden subtrees are
+// ignored when collecting the used classes of a page. An element is hidden if
+// it or one of its ancestors has a computed display of "none", or if its
+// computed visibility is not "visible". Classes that are only found on hidden
+// elements are considered unused and are reported separately in the
+// HiddenOnly field of an [Audit]. This helps to distinguish rendered usage
+// from inert markup like dead UI.
+func WithIgnoreHidden(ignore bool) Option {
+	return func(f *Finder) {
+		f.ignoreHidden = ignore
+	}
+}
+
+// WithClassManifest configures a manifest of a build tool that maps source
+// class names to the class names that are emitted into the production markup,
+// e.g. {"btn": "a1b2"} for CSS Modules or other scoped CSS toolchains. The
+// classes found on the crawled pages are translated back to their source names
+// before they are compared with the defined classes, so that the classes of
+// the source CSS files can be audited against the hashed classes in the DOM.
+// Classes that are not in the manifest are matched by their name. Whitespace
+// around the names of the manifest is trimmed, like the whitespace around the
+// classes of a class attribute, and entries with an empty name are skipped.
+func WithClassManifest(manifest map[string]string) Option {
+	return func(f *Finder) {
+		f.classManifest = make(map[string][]string, len(manifest))
+		for source, emitted := range manifest {
+			source, emitted = strings.TrimSpace(source), strings.TrimSpace(emitted)
+			if source == "" || emitted == "" {
+				continue
+			}
+			f.classManifest[emitted] = append(f.classManifest[emitted], source)
+		}
+		for emitted, sources := range f.classManifest {
+			slices.Sort(sources)
+			f.classManifest[emitted] = slices.Compact(sources)
+		}
+	}
+}
+
+// WithBFS configures whether pages are crawled in breadth-first order. By
+// default, discovered pages are handed to the concurrent workers in no
+// particular order, which mixes pages of different link depths, so that a
+// page limit results in an arbitrary selection of pages. In breadth-first
+// order, pages closer to the root page are visited first, which makes a
+// limited crawl representative of the top layers of the website.
+func WithBFS(bfs bool) Option {
+	return func(f *Finder) {
+		f.bfs = bfs
+	}
+}
+
+// WithDropInvalidClasses configures whether class tokens of the crawled pages
+// that are not valid class names are dropped. Server-side templates that fail
+// to render may leak artifacts into class attributes, like class="btn {{ if
+// active }}active{{ end }}", whose tokens would otherwise be counted as used
+// classes. Only tokens like "{{" are dropped; leaked words that happen to be
+// valid class names, like "if", are still counted. Variants of valid class
+// names like "md:flex" are kept (see [SplitVariants]), but note that the
+// validation drops other unusual, but valid class names like "w-1/2".
+func WithDropInvalidClasses(drop bool) Option {
+	return func(f *Finder) {
+		f.dropInvalidClasses = drop
+	}
+}
+
+// WithOnlyURLs configures the pages whose content changed since the last crawl,
+// e.g. as reported by deploy tooling. It only has an effect if a cache is
+// configured (see [WithCache]): the changed pages are always rendered, while
+// the cached results of all other pages are reused without checking whether
+// they are up to date. Pages that are not cached yet are rendered as usual.
+// This way, the used classes still reflect the whole website, but only the
+// changed pages need to be rendered. URLs are matched by their full URL first,
+// and by their path second, so both "https://example.com/about" and "/about"
+// are accepted.
+func WithOnlyURLs(urls []string) Option {
+	return func(f *Finder) {
+		f.onlyURLs = make(map[string]bool, len(urls))
+		for _, u := range urls {
+			f.onlyURLs[u] = true
+		}
+	}
+}
+
+// WithWebSocketQuiet configures a quiet period to wait for before the classes
+// of a page are extracted: extraction is delayed until no WebSocket message
+// has been received by the page for the given duration. Dev servers push hot
+// module replacement (HMR) updates over WebSockets that mutate the DOM
+// shortly after the page has loaded, so without a quiet period, a transient
+// state of the page may be read. The wait is capped at 30 seconds per page. A
+// duration of 0 disables the wait, which is the default.
+func WithWebSocketQuiet(quiet time.Duration) Option {
+	return func(f *Finder) {
+		f.webSocketQuiet = quiet
+	}
+}
+
+// WithAcceptStatus configures the HTTP status codes of the pages whose classes
+// are extracted. Pages with other status codes are skipped with a
+// [*StatusError], because error pages like a 404 page render a DOM whose
+// classes would otherwise count as used across the website. By default, only
+// 2xx status codes are accepted. Pages whose status code is unknown are
+// always accepted.
+func WithAcceptStatus(codes []int) Option {
+	return func(f *Finder) {
+		f.acceptedStatus = append([]int{}, codes...)
+	}
+}
+
+// WithWARCOutput configures a WARC file that the HTTP requests and responses
+// of all rendered pages are recorded to, including the requests for
+// stylesheets, scripts, and other resources. This allows to re-run offline
+// analyzers against the exact captured website. Records are appended if the
+// file exists. Response bodies are recorded as decoded by the browser. Pages
+// that are served from the cache (see [WithCache]) are not recorded.
+func WithWARCOutput(path string) Option {
+	return func(f *Finder) {
+		f.warc = &warcWriter{path: path}
+	}
+}
+
+// WithCaseInsensitivePrefixes configures class prefixes under which classes
+// are compared case-insensitively when the defined classes are matched against
+// the used classes, e.g. "Heading" for CMS-generated classes like
+// "HeadingLarge" that appear as "headinglarge" in the markup. The prefixes
+// themselves are matched case-insensitively, too. All other classes are
+// still compared case-sensitively.
+func WithCaseInsensitivePrefixes(prefixes []string) Option {
+	return func(f *Finder) {
+		f.caseInsensitivePrefixes = append([]string{}, prefixes...)
+	}
+}
+
+// LoadEvent is the event of a page that is waited for before its classes are
+// extracted (see [WithLoadEvent]).
+type LoadEvent string
+
+const (
+	// LoadEventLoad waits for the "load" event, which fires when the page and
+	// all of its resources have been loaded. This is the default.
+	LoadEventLoad = LoadEvent("load")
+
+	// LoadEventDOMContentLoaded waits for the "DOMContentLoaded" event, which
+	// fires as soon as the HTML has been parsed, without waiting for
+	// stylesheets, images, and other resources.
+	LoadEventDOMContentLoaded = LoadEvent("domcontentloaded")
+
+	// LoadEventNetworkIdle waits until the page has had no network
+	// connections for at least 500 ms, which gives single-page applications
+	// time to fetch and render their content.
+	LoadEventNetworkIdle = LoadEvent("networkidle0")
+)
+
+// lifecycle returns the name of the lifecycle event of the browser that
+// corresponds to the load event, or an empty string for the default "load"
+// event, which is waited for using [rod.Page.WaitLoad].
+func (e LoadEvent) lifecycle() proto.PageLifecycleEventName {
+	switch e {
+	case LoadEventDOMContentLoaded:
+		return proto.PageLifecycleEventNameDOMContentLoaded
+	case LoadEventNetworkIdle:
+		return proto.PageLifecycleEventNameNetworkIdle
+	default:
+		return ""
+	}
+}
+
+// WithLoadEvent configures the event of a page that is waited for before its
+// classes are extracted. For single-page applications, the default "load"
+// event may fire before the content is rendered, which results in classes
+// being reported as unused although they are used; [LoadEventNetworkIdle]
+// waits longer. For heavy pages, [LoadEventDOMContentLoaded] avoids waiting
+// for resources that do not affect the markup.
+func WithLoadEvent(event LoadEvent) Option {
+	return func(f *Finder) {
+		f.loadEvent = event
+	}
+}
+
+// WithMaxDuration configures the maximum duration of a crawl. When it is
+// exceeded, the crawl stops and the results of the pages visited so far are
+// used. Such a crawl is reported as incomplete (see [CrawlStats]). A duration
+// of 0 disables the limit, which is the default.
+func WithMaxDuration(d time.Duration) Option {
+	return func(f *Finder) {
+		f.maxDuration = d
+	}
+}
+
+// WithPrepareScript adds a JavaScript function that is run on every page after
+// it has loaded and before its classes are extracted, e.g. to open disclosure
+// widgets whose conditional classes are only applied when they are open. The
+// script must be a function expression like "() => { ... }"; if it returns a
+// promise, the promise is awaited. Prepare scripts run in the order they were
+// added. Failures are logged and do not fail the page.
+func WithPrepareScript(script string) Option {
+	return func(f *Finder) {
+		f.prepareScripts = append(f.prepareScripts, script)
+	}
+}
+
+// WithExpandAll configures whether a built-in prepare script (see
+// [WithPrepareScript]) opens all disclosure widgets before the classes of a
+// page are extracted: it opens all <details> elements, clicks all elements
+// with aria-expanded="false" like collapsed accordions, and selects every tab
+// once. Elements within links are never clicked. The built-in script runs
+// before all other prepare scripts.
+func WithExpandAll(expand bool) Option {
+	return func(f *Finder) {
+		f.expandAll = expand
+	}
+}
+
+// WithFailureThreshold configures the maximum ratio (0 to 1) of pages that may
+// fail during a crawl. The ratio is checked after every processed page once
+// at least 10 pages have been processed. If it is exceeded, the crawl is
+// aborted early with [ErrTooManyFailures] instead of producing a result that
+// reports most classes as unused. A ratio of 0 disables the check, which is
+// the default.
+func WithFailureThreshold(ratio float64) Option {
+	return func(f *Finder) {
+		f.failureThreshold = ratio
+	}
+}
+
+// WithLoginScript configures a script that logs in to the website before the
+// crawl, for areas that require filling and submitting a login form rather
+// than just setting cookies. The script runs exactly once per crawl, after the
+// browser has been connected to and before any page is crawled. It receives
+// a blank page and is responsible for navigating to the login page and
+// submitting the form. The session it establishes (cookies, storage) is shared
+// by all pages of the crawl, because they are opened in the same browser. If
+// the script fails, the crawl fails with a [CrawlError] at [StageLogin].
+// Siteperf never logs the script or the pages it visits, but errors returned
+// by the script are passed on as is, so they should not contain credentials.
+//
+// Note that the HTTP requests made outside of the browser, e.g. by the cache
+// (see [WithCache]), do not share the session.
+func WithLoginScript(script func(page *rod.Page) error) Option {
+	return func(f *Finder) {
+		f.loginScript = script
+	}
+}
+
+// WithAllowedHosts configures additional hosts whose pages are crawled. By
+// default, only links to the host of the root URL are followed. Hosts are
+// matched exactly, including the port, so "example.com" does not allow
+// "www.example.com". Note that robots.txt (see [WithRespectRobots]) is only
+// fetched from the host of the root URL.
+func WithAllowedHosts(hosts ...string) Option {
+	return func(f *Finder) {
+		if f.allowedHosts == nil {
+			f.allowedHosts = make(map[string]bool, len(hosts))
+		}
+		for _, host := range hosts {
+			f.allowedHosts[host] = true
+		}
+	}
+}
+
+// WithPerHostConcurrency limits the number of pages of the same host that are
+// visited concurrently. In crawls across multiple hosts (see
+// [WithAllowedHosts]), this prevents a single host from being hammered and
+// keeps a slow host from occupying all workers. A limit of 0 disables the
+// limit, which is the default.
+func WithPerHostConcurrency(n int) Option {
+	return func(f *Finder) {
+		f.perHostConcurrency = n
+	}
+}
+
+// WithMaxConcurrentNavigations limits the number of pages that navigate at the
+// same time. A page holds its navigation slot from the moment it is opened
+// until it has loaded, so that a burst of newly discovered links does not
+// start many navigations at once and overload the browser. The limit is
+// shared by all crawls of the Finder. A limit of 0 disables the limit, which
+// is the default.
+func WithMaxConcurrentNavigations(n int) Option {
+	return func(f *Finder) {
+		f.navigations = nil
+		if n > 0 {
+			f.navigations = make(chan struct{}, n)
+		}
+	}
+}
+
+// WithOnNewClass configures a function that is called the first time each
+// distinct class is found during a crawl, as soon as the page it was found on
+// has been crawled. Unlike the usage counts, which are only available when the
+// crawl has finished, this allows to display newly discovered classes live.
+// Classes that are only found within hidden subtrees (see [WithIgnoreHidden])
+// are not reported. The function is never called concurrently, but it blocks
+// the crawl while it runs, so it should return quickly.
+func WithOnNewClass(fn func(class string)) Option {
+	return func(f *Finder) {
+		f.onNewClass = fn
+	}
+}
+
+// WithAcceptLanguage configures the language that is requested from the
+// website, e.g. "de-DE" or "fr-CH, fr;q=0.9, en;q=0.8". The value is sent as
+// the Accept-Language header of all requests, and it is reported by
+// navigator.language and navigator.languages within the pages, so that
+// localized websites serve the markup of that locale. By default, the
+// language of the browser is used.
+func WithAcceptLanguage(lang string) Option {
+	return func(f *Finder) {
+		f.acceptLanguage = lang
+	}
+}
+
+// WithConcurrency configures the number of pages that are crawled
+// concurrently. Every worker keeps a page of the browser open, so more workers
+// speed up the crawl of large websites only as long as the browser and the
+// website keep up. By default, or if n is 0 or less, the number of CPUs is
+// used, but at most 8.
+func WithConcurrency(n int) Option {
+	return func(f *Finder) {
+		f.concurrency = n
+	}
+}
+
+// WithCanonicalizeQuery configures a function that reduces the query
+// parameters of discovered links to a canonical form before they are
+// deduplicated and crawled. By default, links that only differ in their query
+// are crawled once, using the query of the first discovered link. With a
+// canonicalization function, each distinct canonical query of a path is
+// crawled once, which allows to crawl relevant variants of a page while
+// avoiding the explosion of parameter permutations of faceted navigation:
+//
+//	WithCanonicalizeQuery(func(q url.Values) url.Values {
+//		return url.Values{"sort": q["sort"]}
+//	})
+//
+// Parameters without values are dropped from the canonical query, and the
+// remaining ones are sorted by name.
+func WithCanonicalizeQuery(fn func(url.Values) url.Values) Option {
+	return func(f *Finder) {
+		f.canonicalizeQuery = fn
+	}
+}
+
+// WithBrowserReconnects configures how often the browser is relaunched if it
+// crashes or disconnects during a crawl. When a page fails because the browser
+// is gone, a new browser is launched (running the login script again, if
+// configured, see [WithLoginScript]) and the page is visited again, so that a
+// single crash does not fail all remaining pages of a long crawl. By default,
+// the browser is never relaunched.
+func WithBrowserReconnects(n int) Option {
+	return func(f *Finder) {
+		f.browserReconnects = n
+	}
+}
+
+// WithExcludeAMPClasses excludes the classes that the AMP runtime adds to the
+// elements of AMP pages from the used classes, so that an audit of AMP pages
+// only reflects the classes of the website itself. The classes are matched by
+// the given prefixes, or by [DefaultAMPPrefixes] if none are given. Note that
+// the "amp-" prefix also matches own classes like "amp-card", which are then
+// excluded as well; pass a narrower set of prefixes to keep them.
+func WithExcludeAMPClasses(prefixes ...string) Option {
+	return func(f *Finder) {
+		if len(prefixes) == 0 {
+			prefixes = DefaultAMPPrefixes
+		}
+		f.ampPrefixes = slices.Clone(prefixes)
+	}
+}
+
+// WithUserDataDir launches the browser with a persistent profile in the given
+// directory, which is created if it does not exist. Cookies, local storage,
+// and other session data survive between crawls, so that a session that was
+// established once, e.g. by a login script (see [WithLoginScript]), can be
+// reused by later runs. A profile can only be used by one browser at a time,
+// so crawls that use the same directory must not run concurrently. By default,
+// every browser uses a fresh temporary profile.
+func WithUserDataDir(dir string) Option {
+	return func(f *Finder) {
+		f.userDataDir = dir
+	}
+}
+
+// WithDedupByStructure configures whether pages whose elements have the same
+// tag names and class attributes as an already crawled page are deduplicated.
+// Templated pages like product pages or profiles often only differ in their
+// text, so their classes do not need to be extracted again. The links of such
+// pages are still followed, but their classes only count once towards the
+// usage counts, and their stylesheets and other data are not extracted. Pages
+// that are deduplicated are not cached (see [WithCache]). Note that the
+// structure does not account for the visibility of elements (see
+// [WithIgnoreHidden]).
+func WithDedupByStructure(dedup bool) Option {
+	return func(f *Finder) {
+		f.dedupByStructure = dedup
+	}
+}
+
+// WithSVGUseReferences configures whether the ids of the SVG symbols that are
+// referenced by <use> elements are counted as used classes. Icon systems
+// commonly name their classes after the symbols of a sprite, like ".icon-foo"
+// for the symbol that is referenced by <use href="#icon-foo">, and style them
+// without the class appearing in any class attribute. Both the href and the
+// legacy xlink:href attribute are read, and references into external sprites
+// ("/sprite.svg#icon-foo") count as well.
+//
+// Classes on the <symbol> elements of inline sprites are always counted, like
+// the classes of any other element. The classes within external sprites are
+// not visible to the crawl, because the browser instantiates their symbols in
+// closed shadow trees.
+func WithSVGUseReferences(enabled bool) Option {
+	return func(f *Finder) {
+		f.svgUseReferences = enabled
+	}
+}
+
+// WithIncludeFrames configures whether the classes within the same-origin
+// <iframe> elements of a page are counted as used, including those of frames
+// that are nested within them. By default, only the classes of the top-level
+// document are extracted, which misses the classes of embedded views that are
+// styled by the same stylesheets. Cross-origin frames cannot be accessed from
+// the page and are skipped.
+func WithIncludeFrames(enabled bool) Option {
+	return func(f *Finder) {
+		f.includeFrames = enabled
+	}
+}
+
+// WithShouldCrawl configures a callback that decides whether a discovered link
+// is crawled. It is called with the link, after the fragment mode and the
+// canonical query have been applied, and the depth at which the link would be
+// crawled: the root page is at depth 0 and the links on it are at depth 1. If
+// the callback returns false, the link is skipped and does not count towards
+// the page limit.
+//
+// The callback is only called for links that pass all other checks and have
+// not been visited yet. A skipped link may be discovered on other pages, in
+// which case the callback is called again. The callback is called concurrently
+// by the workers of the crawl (see [WithConcurrency]) and must be safe for
+// concurrent use.
+func WithShouldCrawl(fn func(u *url.URL, depth int) bool) Option {
+	return func(f *Finder) {
+		f.shouldCrawl = fn
+	}
+}
+
+// WithExtractTimeout configures the time budget for the extraction of classes,
+// measured from the start of a crawl. Once it is exhausted, the crawl goes on
+// to discover the remaining pages, but they are only rendered to find their
+// links; their classes, stylesheets, and other data are no longer extracted.
+// This caps the expensive part of crawls of sites with slow pages, while the
+// link graph and the page manifest stay complete. Such a crawl is reported as
+// incomplete with [IncompleteExtractTimeout], because classes that are only
+// used on the skipped pages are reported as unused.
+//
+// Pages that are inspected further, like by [Finder.AssetUsage] and
+// [Finder.EffectiveUsage], are always extracted. A budget of 0 disables the
+// limit, which is the default. To limit the duration of the whole crawl, use
+// [WithMaxDuration].
+func WithExtractTimeout(d time.Duration) Option {
+	return func(f *Finder) {
+		f.extractTimeout = d
+	}
+}
+
+// WithIgnoreClasses configures classes that are never reported as unused,
+// like classes that are only added by scripts in response to user input.
+// Besides exact class names, glob patterns are supported, in which "*" matches
+// any sequence of characters and "?" matches any single character, e.g.
+// "is-*" or "col-?". Ignored classes are excluded from the defined classes of
+// an audit, so they do not count towards its coverage either. Use
+// [ParseIgnoreFile] to read the patterns from a file.
+func WithIgnoreClasses(patterns ...string) Option {
+	return func(f *Finder) {
+		f.ignoredClasses = newIgnoredClasses(patterns)
+	}
+}
+
+// WithModifiedSince restricts the extraction of classes to the pages that were
+// modified after t. Before a page is rendered, a HEAD request with an
+// If-Modified-Since header is sent, and the page is considered unmodified if
+// the server responds with 304 Not Modified or a Last-Modified header that is
+// not after t. The cached result of an unmodified page is used if a cache is
+// configured (see [WithCache]). Otherwise, the page is only rendered to
+// discover its links, and its classes are missing from the result.
+//
+// Pages whose modification time is unknown are always extracted. Use this to
+// scope audits of large archives to recently changed content; classes that are
+// only used on unmodified, uncached pages are reported as unused.
+func WithModifiedSince(t time.Time) Option {
+	return func(f *Finder) {
+		f.modifiedSince = t
+	}
+}
+
+// WithRespectMetaRobots configures whether the <meta name="robots"> directives
+// of the crawled pages are respected. The links of a page that declares
+// "nofollow" are not followed, and the classes of a page that declares
+// "noindex" are not counted as used, because such pages are usually not
+// canonical content, like search results or print views. The "none" directive
+// implies both. Unlike the rules of robots.txt (see [WithRespectRobots]), these
+// directives are only known once the page has been rendered, so such pages
+// are still visited.
+func WithRespectMetaRobots(respect bool) Option {
+	return func(f *Finder) {
+		f.respectMetaRobots = respect
+	}
+}
+
+// WithElementSample limits the extraction of classes to a random sample of at
+// most n elements with a class attribute per page (and per frame, see
+// [WithIncludeFrames]). Pages with enormous DOMs can take a long time to
+// extract; sampling trades the completeness of their classes for speed.
+// Classes that are only used on few elements of a sampled page are likely to
+// be missed, so the usage of such a crawl is approximate: the number of
+// sampled pages is reported in [CrawlStats.SampledPages], and the results of
+// sampled pages are not cached. Pages with at most n such elements are
+// extracted completely. A limit of 0 disables sampling, which is the default.
+func WithElementSample(n int) Option {
+	return func(f *Finder) {
+		f.elementSample = n
+	}
+}
+
+// WithTracerProvider configures the OpenTelemetry tracer provider that records
+// the spans of the crawls of the Finder. Every crawl is recorded as a
+// "siteperf.crawl" span with the number of visited and failed pages, every
+// visited page as a child "siteperf.page" span, and its navigation and
+// extraction as the "siteperf.navigate" and "siteperf.extract" spans within
+// it. Failed pages are recorded as errors. By default, no spans are recorded.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(f *Finder) {
+		f.tracerProvider = tp
+	}
+}
+
+// WithRoleFilter restricts the extraction of classes to the elements with one
+// of the given ARIA roles, like "button", "link", or "menuitem". An element
+// has a role if its role attribute contains it, or, if it has no role
+// attribute, if the role is implicit for its element type, like "button" for
+// <button> and <input type="submit">, or "link" for <a href>. The classes of
+// all other elements are ignored, so an audit answers which of the classes
+// that are meant for elements of these roles are unused.
+//
+// The filter only affects the class attributes of the crawled pages: classes
+// found within script markup (see [WithScriptMarkup]), SVG references (see
+// [WithSVGUseReferences]), and the elements matched by attribute selectors
+// (see [WithAttributeSelectors]) are counted regardless of the role. If
+// elements are sampled (see [WithElementSample]), the sample is drawn from the
+// elements with a matching role. Without roles, all elements are considered,
+// which is the default.
+func WithRoleFilter(roles []string) Option {
+	return func(f *Finder) {
+		f.roleSelector = roleSelector(roles)
+	}
+}
+
+// WithBloomVisited tracks the visited pages of a crawl with a bloom filter
+// instead of a set of their URLs. The memory of the filter is fixed by the
+// estimated number of pages and the false-positive rate, e.g. about 1.8 MB for
+// a million pages at a rate of 0.001, while the set grows with the length and
+// number of the URLs. In exchange, a page that was not visited is skipped at
+// the given false-positive rate, because the filter reports it as visited, and
+// the rate increases if the crawl visits more pages than estimated. This is
+// meant for crawls of hundreds of thousands of pages. A rate outside of
+// (0, 1) uses a rate of 0.001. An estimate of 0 uses the exact set, which is
+// the default.
+func WithBloomVisited(estimatedPages int, fpRate float64) Option {
+	return func(f *Finder) {
+		f.bloomVisited = estimatedPages
+		f.bloomFPRate = fpRate
+	}
+}
+
+// WithPageLimit configures the maximum number of pages that are crawled, in
+// addition to the root page, overriding the page limit that is passed to
+// [New]. It allows to limit the crawls of Finders that are created by others,
+// like those of [AuditMulti]. A limit of 0 or less crawls all reachable pages.
+func WithPageLimit(n int) Option {
+	return func(f *Finder) {
+		f.pageLimit = max(n, 0)
+	}
+}
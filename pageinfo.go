@@ -0,0 +1,84 @@
+package siteperf
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// PageInfo describes a single page of a crawl, as returned by
+// [Finder.PageManifest].
+type PageInfo struct {
+	// URL is the URL of the page.
+	URL string `json:"url"`
+
+	// StatusCode is the status code of the response of the page, or 0 if it
+	// is unknown.
+	StatusCode int `json:"statusCode"`
+
+	// LoadTime is the time it took to open and load the page in the browser.
+	LoadTime time.Duration `json:"loadTime"`
+
+	// Classes is the number of distinct classes found on the page.
+	Classes int `json:"classes"`
+
+	// Cached reports whether the page was loaded from the cache (see
+	// [WithCache]), in which case StatusCode and LoadTime are the ones of the
+	// cached render.
+	Cached bool `json:"cached,omitempty"`
+
+	// Sampled reports whether Classes is based on a random sample of the
+	// elements of the page (see [WithElementSample]) and therefore a lower
+	// bound.
+	Sampled bool `json:"sampled,omitempty"`
+
+	// Error is the error of the page if it could not be visited.
+	Error string `json:"error,omitempty"`
+}
+
+// PageManifest crawls the website of the Finder and returns a [PageInfo] for
+// every page that was visited, including the pages that could not be visited,
+// in the order in which they were crawled. This provides an inventory of the
+// website that is independent of the CSS analysis.
+func (f *Finder) PageManifest(ctx context.Context) ([]PageInfo, error) {
+	browser, err := f.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []PageInfo
+	_, err = f.stream(ctx, browser, nil, func(outcome pageOutcome) bool {
+		out = append(out, outcome.info())
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (o pageOutcome) info() PageInfo {
+	if o.err != nil {
+		info := PageInfo{URL: o.url, Error: o.err.Error()}
+		var statusErr *StatusError
+		if errors.As(o.err, &statusErr) {
+			info.StatusCode = statusErr.StatusCode
+		}
+		return info
+	}
+
+	info := PageInfo{
+		URL:        o.url,
+		StatusCode: o.result.status,
+		LoadTime:   o.result.loadTime,
+		Cached:     o.result.cached,
+		Sampled:    o.result.sampled,
+	}
+	for _, class := range o.result.classes {
+		if class.count > 0 {
+			info.Classes++
+		}
+	}
+	return info
+}
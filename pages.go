@@ -0,0 +1,92 @@
+package siteperf
+
+import (
+	"context"
+	"errors"
+	"iter"
+)
+
+// PageResult is the result of a single crawled page.
+type PageResult struct {
+	// URL is the URL of the page.
+	URL string `json:"url"`
+
+	// FinalURL is the URL of the page after all redirects. It is only set if
+	// the page was redirected, e.g. from http to https.
+	FinalURL string `json:"finalUrl,omitempty"`
+
+	// Classes maps every class found on the page to the number of elements it
+	// was found on.
+	Classes map[string]int `json:"classes"`
+
+	// Stylesheets contains the absolute URLs of the stylesheets that are
+	// linked by the page.
+	Stylesheets []string `json:"stylesheets,omitempty"`
+
+	// Links contains the URLs of the links on the page that point to the host
+	// of the root URL.
+	Links []string `json:"links,omitempty"`
+}
+
+// Pages crawls the website of the Finder and returns an iterator over the
+// crawled pages, which yields every page as soon as it has been crawled:
+//
+//	for page, err := range f.Pages(ctx) {
+//		if err != nil {
+//			log.Println(err)
+//			continue
+//		}
+//		fmt.Println(page.URL, len(page.Classes))
+//	}
+//
+// Pages that cannot be visited are yielded with an error (usually a
+// [*CrawlError]) and a PageResult that only has its URL set. If the browser
+// cannot be connected to, a single error is yielded. If the crawl is aborted
+// because too many pages failed (see [WithFailureThreshold]),
+// [ErrTooManyFailures] is yielded last. Breaking out of the loop stops the
+// crawl.
+func (f *Finder) Pages(ctx context.Context) iter.Seq2[PageResult, error] {
+	return func(yield func(PageResult, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		browser, err := f.connect(ctx)
+		if err != nil {
+			yield(PageResult{URL: f.rootURL.String()}, err)
+			return
+		}
+
+		_, err = f.stream(ctx, browser, nil, func(outcome pageOutcome) bool {
+			if outcome.err != nil {
+				return yield(PageResult{URL: outcome.url}, outcome.err)
+			}
+			return yield(outcome.result.export(), nil)
+		})
+
+		// The error that stream returns if the root page fails has already
+		// been yielded for the root page.
+		if errors.Is(err, ErrTooManyFailures) {
+			yield(PageResult{}, err)
+		}
+	}
+}
+
+func (r pageResult) export() PageResult {
+	out := PageResult{
+		URL:         r.url,
+		Classes:     make(map[string]int, len(r.classes)),
+		Stylesheets: r.stylesheets,
+	}
+	if r.finalURL != r.url {
+		out.FinalURL = r.finalURL
+	}
+	for _, class := range r.classes {
+		if class.count > 0 {
+			out.Classes[class.class] += class.count
+		}
+	}
+	for _, link := range r.links {
+		out.Links = append(out.Links, link.String())
+	}
+	return out
+}
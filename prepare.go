@@ -0,0 +1,55 @@
+package siteperf
+
+import (
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+// expandAllScript opens all disclosure widgets of a page: <details> elements,
+// collapsed accordions and menus (elements with aria-expanded="false"), and
+// tabs. Links are never clicked, so that the page does not navigate away.
+const expandAllScript = `async () => {
+	for (const el of document.querySelectorAll("details:not([open])")) {
+		el.open = true
+	}
+
+	const clickable = (el) => !el.closest("a[href]") && !el.disabled && el.getAttribute("aria-disabled") !== "true"
+
+	for (const el of document.querySelectorAll("[aria-expanded=false]")) {
+		if (clickable(el)) {
+			el.click()
+		}
+	}
+
+	// Every tab is selected once, so that the classes of all tab panels are
+	// applied at least once.
+	for (const el of document.querySelectorAll("[role=tab]")) {
+		if (clickable(el)) {
+			el.click()
+			await new Promise((resolve) => setTimeout(resolve, 50))
+		}
+	}
+}`
+
+// preparePage runs the configured prepare scripts on the page and waits for
+// the page to become stable again.
+func (f *Finder) preparePage(page *rod.Page, pageUrl string) {
+	scripts := f.prepareScripts
+	if f.expandAll {
+		scripts = append([]string{expandAllScript}, scripts...)
+	}
+	if len(scripts) == 0 {
+		return
+	}
+
+	for _, script := range scripts {
+		if _, err := page.Eval(script); err != nil {
+			f.log.Warn("Failed to run prepare script", "url", pageUrl, "err", err)
+		}
+	}
+
+	if err := page.WaitStable(100 * time.Millisecond); err != nil {
+		f.log.Warn("Failed to wait for page stability after prepare scripts", "url", pageUrl, "err", err)
+	}
+}
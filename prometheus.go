@@ -0,0 +1,52 @@
+package siteperf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WritePrometheus writes the key figures of the audit in the Prometheus text
+// exposition format, e.g. for the textfile collector of the node exporter.
+// Every metric is labeled with the given root URL, so that the audits of
+// multiple websites can be told apart:
+//
+//	siteperf_unused_classes{root="https://example.com"} 42
+//	siteperf_coverage_ratio{root="https://example.com"} 0.73
+func WritePrometheus(w io.Writer, audit Audit, root string) error {
+	bw := bufio.NewWriter(w)
+	labels := fmt.Sprintf(`{root="%s"}`, escapePrometheusLabel(root))
+
+	incomplete := 0
+	if audit.Crawl.Incomplete {
+		incomplete = 1
+	}
+
+	for _, m := range []struct {
+		name  string
+		help  string
+		value float64
+	}{
+		{"siteperf_defined_classes", "Number of distinct defined classes.", float64(audit.Coverage.Defined)},
+		{"siteperf_used_classes", "Number of defined classes that are used.", float64(audit.Coverage.Used)},
+		{"siteperf_unused_classes", "Number of defined classes that are unused.", float64(audit.Coverage.Unused)},
+		{"siteperf_coverage_ratio", "Ratio of defined classes that are used.", audit.Coverage.Percent / 100},
+		{"siteperf_undefined_classes", "Number of used classes that are not defined.", float64(len(audit.Undefined))},
+		{"siteperf_pages_crawled", "Number of pages that were crawled successfully.", float64(audit.Crawl.Visited)},
+		{"siteperf_pages_failed", "Number of pages that could not be crawled.", float64(audit.Crawl.Failed)},
+		{"siteperf_crawl_duration_seconds", "Duration of the crawl in seconds.", audit.Crawl.Elapsed.Seconds()},
+		{"siteperf_crawl_incomplete", "Whether the crawl stopped before all reachable pages were visited.", float64(incomplete)},
+	} {
+		fmt.Fprintf(bw, "# HELP %s %s\n", m.name, m.help)
+		fmt.Fprintf(bw, "# TYPE %s gauge\n", m.name)
+		fmt.Fprintf(bw, "%s%s %s\n", m.name, labels, strconv.FormatFloat(m.value, 'g', -1, 64))
+	}
+
+	return bw.Flush()
+}
+
+func escapePrometheusLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,75 @@
+package siteperf
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// browserConn is the browser of a crawl, which is relaunched if it crashes
+// (see [WithBrowserReconnects]). A browserConn is safe for concurrent use.
+type browserConn struct {
+	f *Finder
+
+	mux        sync.Mutex
+	browser    *rod.Browser
+	reconnects int
+}
+
+func (f *Finder) newBrowserConn(browser *rod.Browser) *browserConn {
+	return &browserConn{f: f, browser: browser}
+}
+
+// get returns the current browser.
+func (c *browserConn) get() *rod.Browser {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.browser
+}
+
+// recover checks whether the given browser, which failed to visit a page, is
+// still connected. If it is not, the browser is relaunched, unless the
+// maximum number of reconnects is exhausted. It reports whether the page
+// should be visited again using the current browser, which is the case if
+// the browser was relaunched, either by this call or concurrently by another
+// worker.
+func (c *browserConn) recover(ctx context.Context, failed *rod.Browser) bool {
+	if c.f.browserReconnects <= 0 || ctx.Err() != nil {
+		return false
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if c.browser != failed {
+		return true
+	}
+
+	if _, err := (proto.BrowserGetVersion{}).Call(failed); err == nil {
+		return false
+	}
+
+	if c.reconnects >= c.f.browserReconnects {
+		return false
+	}
+	c.reconnects++
+
+	c.f.log.Warn("Browser disconnected, relaunching", "attempt", c.reconnects, "maxAttempts", c.f.browserReconnects)
+	failed.Close()
+
+	browser, err := c.f.launch(ctx)
+	if err != nil {
+		c.f.log.Warn("Failed to relaunch browser", "err", err)
+		return false
+	}
+	c.browser = browser
+
+	return true
+}
+
+// close closes the current browser.
+func (c *browserConn) close() error {
+	return c.get().Close()
+}
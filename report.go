@@ -0,0 +1,82 @@
+package siteperf
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+)
+
+// maxReportPrefixes is the number of prefixes listed in the "Top unused
+// prefixes" section of a report.
+const maxReportPrefixes = 10
+
+// WriteReport writes a human-readable summary of the audit to w. The report
+// contains the number of crawled and failed pages, the coverage of the defined
+// classes, the prefixes with the most unused classes (see [GroupByPrefix]),
+// and the list of unused classes. The diagnostics of the audit, if any, are
+// written first.
+func WriteReport(w io.Writer, a Audit) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	for _, d := range a.Diagnostics {
+		fmt.Fprintf(tw, "WARNING: %s\n", d.Message)
+	}
+	if len(a.Diagnostics) > 0 {
+		fmt.Fprintln(tw)
+	}
+
+	fmt.Fprintf(tw, "Pages crawled:\t%d\n", a.Crawl.Visited)
+	fmt.Fprintf(tw, "Pages failed:\t%d\n", a.Crawl.Failed)
+	if a.Crawl.SampledPages > 0 {
+		fmt.Fprintf(tw, "Pages sampled:\t%d (usage is approximate)\n", a.Crawl.SampledPages)
+	}
+	if a.Crawl.Incomplete {
+		fmt.Fprintf(tw, "Incomplete:\t%s\n", a.Crawl.IncompleteReason)
+	}
+	fmt.Fprintf(tw, "Defined classes:\t%d\n", a.Coverage.Defined)
+	fmt.Fprintf(tw, "Used classes:\t%d\n", a.Coverage.Used)
+	fmt.Fprintf(tw, "Unused classes:\t%d\n", a.Coverage.Unused)
+	fmt.Fprintf(tw, "Coverage:\t%.1f%%\n", a.Coverage.Percent)
+
+	if prefixes := topPrefixes(a.Unused, maxReportPrefixes); len(prefixes) > 0 {
+		fmt.Fprintf(tw, "\nTop unused prefixes:\n")
+		for _, p := range prefixes {
+			fmt.Fprintf(tw, "  %s\t%d\n", p.prefix, p.count)
+		}
+	}
+
+	if len(a.Unused) > 0 {
+		fmt.Fprintf(tw, "\nUnused classes:\n")
+		for _, class := range a.Unused {
+			fmt.Fprintf(tw, "  %s\n", class)
+		}
+	}
+
+	return tw.Flush()
+}
+
+type prefixCount struct {
+	prefix string
+	count  int
+}
+
+// topPrefixes returns at most n prefixes of the given classes, ordered by the
+// number of classes with that prefix and then by name.
+func topPrefixes(classes []string, n int) []prefixCount {
+	groups := GroupByPrefix(classes)
+
+	out := make([]prefixCount, 0, len(groups))
+	for prefix, group := range groups {
+		out = append(out, prefixCount{prefix: prefix, count: len(group)})
+	}
+	slices.SortFunc(out, func(a, b prefixCount) int {
+		if c := cmp.Compare(b.count, a.count); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.prefix, b.prefix)
+	})
+
+	return out[:min(n, len(out))]
+}
@@ -0,0 +1,168 @@
+package siteperf
+
+import (
+	"bufio"
+	"context"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// robotsUserAgent is the user agent token that is matched against the
+// User-agent lines of robots.txt files, in addition to "*".
+const robotsUserAgent = "siteperf"
+
+// robotsRules are the rules of a robots.txt file that apply to siteperf.
+type robotsRules struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+type robotsRule struct {
+	allow   bool
+	pattern string
+	re      *regexp.Regexp
+}
+
+// loadRobots fetches and parses the robots.txt file of the root URL. It
+// returns nil if robots.txt is not respected or cannot be fetched, in which
+// case everything may be crawled.
+func (f *Finder) loadRobots(ctx context.Context) *robotsRules {
+	if !f.respectRobots {
+		return nil
+	}
+
+	robotsURL := &url.URL{Scheme: f.rootURL.Scheme, Host: f.rootURL.Host, Path: "/robots.txt"}
+
+	body, err := f.fetch(ctx, robotsURL.String())
+	if err != nil {
+		f.log.Debug("Failed to fetch robots.txt", "url", robotsURL, "err", err)
+		return nil
+	}
+
+	return parseRobots(string(body), robotsUserAgent)
+}
+
+// parseRobots parses a robots.txt file and returns the rules of the group that
+// matches the given user agent, falling back to the "*" group.
+func parseRobots(txt, userAgent string) *robotsRules {
+	var (
+		specific, wildcard *robotsRules
+		current            []*robotsRules
+		inRules            bool
+	)
+
+	scanner := bufio.NewScanner(strings.NewReader(txt))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// Consecutive User-agent lines share the same group.
+			if inRules {
+				current = nil
+				inRules = false
+			}
+			// An empty User-agent line starts a group that applies to no
+			// crawler, although every user agent contains the empty string.
+			agent := strings.ToLower(value)
+			switch {
+			case agent == "":
+				current = append(current, nil)
+			case agent == "*":
+				if wildcard == nil {
+					wildcard = &robotsRules{}
+				}
+				current = append(current, wildcard)
+			case strings.Contains(strings.ToLower(userAgent), agent):
+				if specific == nil {
+					specific = &robotsRules{}
+				}
+				current = append(current, specific)
+			default:
+				current = append(current, nil)
+			}
+		case "allow", "disallow":
+			inRules = true
+			if value == "" {
+				// An empty Disallow allows everything.
+				continue
+			}
+			rule := robotsRule{allow: key == "allow", pattern: value, re: robotsPattern(value)}
+			for _, group := range current {
+				if group != nil {
+					group.rules = append(group.rules, rule)
+				}
+			}
+		case "crawl-delay":
+			inRules = true
+			seconds, err := strconv.ParseFloat(value, 64)
+			if err != nil || seconds < 0 {
+				continue
+			}
+			for _, group := range current {
+				if group != nil {
+					group.crawlDelay = time.Duration(seconds * float64(time.Second))
+				}
+			}
+		}
+	}
+
+	if specific != nil {
+		return specific
+	}
+	if wildcard != nil {
+		return wildcard
+	}
+	return &robotsRules{}
+}
+
+// allowed reports whether the given URL may be crawled. The longest matching
+// rule wins; if an Allow and a Disallow rule match with the same length, the
+// Allow rule wins.
+func (r *robotsRules) allowed(u *url.URL) bool {
+	if r == nil {
+		return true
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+
+	allow, longest := true, -1
+	for _, rule := range r.rules {
+		if !rule.re.MatchString(path) {
+			continue
+		}
+		if n := len(rule.pattern); n > longest || (n == longest && rule.allow) {
+			allow, longest = rule.allow, n
+		}
+	}
+	return allow
+}
+
+// robotsPattern compiles a robots.txt path pattern, which may contain "*"
+// wildcards and a trailing "$" anchor, into a regular expression.
+func robotsPattern(pattern string) *regexp.Regexp {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*")
+	if anchored {
+		expr += "$"
+	}
+
+	return regexp.MustCompile(expr)
+}
@@ -0,0 +1,116 @@
+package siteperf
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestParseRobots(t *testing.T) {
+	tests := []struct {
+		name       string
+		txt        string
+		allowed    map[string]bool
+		crawlDelay time.Duration
+	}{
+		{
+			name:    "no rules",
+			txt:     "",
+			allowed: map[string]bool{"/": true, "/admin": true},
+		},
+		{
+			name:    "wildcard group",
+			txt:     "User-agent: *\nDisallow: /admin",
+			allowed: map[string]bool{"/": true, "/admin": false, "/admin/users": false},
+		},
+		{
+			name: "specific group takes precedence over wildcard",
+			txt: "User-agent: *\nDisallow: /\n\n" +
+				"User-agent: siteperf\nDisallow: /private",
+			allowed: map[string]bool{"/": true, "/private": false},
+		},
+		{
+			name: "specific group before wildcard",
+			txt: "User-agent: siteperf\nAllow: /\n\n" +
+				"User-agent: *\nDisallow: /",
+			allowed: map[string]bool{"/": true, "/admin": true},
+		},
+		{
+			name: "group of other agent",
+			txt: "User-agent: Googlebot\nDisallow: /\n\n" +
+				"User-agent: *\nDisallow: /admin",
+			allowed: map[string]bool{"/": true, "/admin": false},
+		},
+		{
+			name:    "only other agent",
+			txt:     "User-agent: Googlebot\nDisallow: /",
+			allowed: map[string]bool{"/": true},
+		},
+		{
+			name:    "agent matched case-insensitively as substring",
+			txt:     "User-agent: SitePerf\nDisallow: /",
+			allowed: map[string]bool{"/": false},
+		},
+		{
+			name:    "consecutive user agents share a group",
+			txt:     "User-agent: Googlebot\nUser-agent: siteperf\nDisallow: /shared",
+			allowed: map[string]bool{"/": true, "/shared": false},
+		},
+		{
+			name: "empty user agent",
+			txt: "User-agent:\nDisallow: /\n\n" +
+				"User-agent: *\nDisallow: /admin",
+			allowed: map[string]bool{"/": true, "/admin": false},
+		},
+		{
+			name:    "only empty user agent",
+			txt:     "User-agent: \nDisallow: /",
+			allowed: map[string]bool{"/": true},
+		},
+		{
+			name:    "longest match wins",
+			txt:     "User-agent: *\nDisallow: /docs\nAllow: /docs/public",
+			allowed: map[string]bool{"/docs/internal": false, "/docs/public/intro": true},
+		},
+		{
+			name:    "wildcards and anchors",
+			txt:     "User-agent: *\nDisallow: /*.pdf$\nDisallow: /*?print=",
+			allowed: map[string]bool{"/a.pdf": false, "/a.pdf.html": true, "/a?print=1": false, "/a": true},
+		},
+		{
+			name:       "crawl delay of specific group",
+			txt:        "User-agent: *\nCrawl-delay: 10\n\nUser-agent: siteperf\nCrawl-delay: 0.5",
+			allowed:    map[string]bool{"/": true},
+			crawlDelay: 500 * time.Millisecond,
+		},
+		{
+			name:       "crawl delay of wildcard group",
+			txt:        "User-agent: Googlebot\nCrawl-delay: 10\n\nUser-agent: *\nCrawl-delay: 2",
+			allowed:    map[string]bool{"/": true},
+			crawlDelay: 2 * time.Second,
+		},
+		{
+			name:    "comments",
+			txt:     "# rules\nUser-agent: * # everyone\nDisallow: /tmp # temporary files",
+			allowed: map[string]bool{"/tmp": false, "/": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := parseRobots(tt.txt, robotsUserAgent)
+			for path, want := range tt.allowed {
+				u, err := url.Parse("https://example.com" + path)
+				if err != nil {
+					t.Fatalf("parse URL: %v", err)
+				}
+				if got := rules.allowed(u); got != want {
+					t.Errorf("allowed(%q) = %t, want %t", path, got, want)
+				}
+			}
+			if rules.crawlDelay != tt.crawlDelay {
+				t.Errorf("crawl delay is %s, want %s", rules.crawlDelay, tt.crawlDelay)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package siteperf
+
+import (
+	"strconv"
+	"strings"
+)
+
+// implicitRoles maps ARIA roles to the selectors of the elements that have
+// the role implicitly, i.e. without a role attribute, following the HTML-ARIA
+// mapping. Roles whose implicit elements depend on the context within the
+// document, like "banner" for <header>, are approximated.
+var implicitRoles = map[string][]string{
+	"article":       {"article"},
+	"banner":        {"header"},
+	"button":        {"button", `input[type="button" i]`, `input[type="submit" i]`, `input[type="reset" i]`, `input[type="image" i]`, "summary"},
+	"cell":          {"td"},
+	"checkbox":      {`input[type="checkbox" i]`},
+	"columnheader":  {"th"},
+	"combobox":      {"select:not([multiple]):not([size])", "input[list]"},
+	"complementary": {"aside"},
+	"contentinfo":   {"footer"},
+	"dialog":        {"dialog"},
+	"figure":        {"figure"},
+	"form":          {"form"},
+	"group":         {"fieldset", "details", "optgroup"},
+	"heading":       {"h1", "h2", "h3", "h4", "h5", "h6"},
+	"img":           {"img:not([alt=''])"},
+	"link":          {"a[href]", "area[href]"},
+	"list":          {"ul", "ol", "menu"},
+	"listbox":       {"select[multiple]", "select[size]", "datalist"},
+	"listitem":      {"li"},
+	"main":          {"main"},
+	"navigation":    {"nav"},
+	"option":        {"option"},
+	"progressbar":   {"progress"},
+	"radio":         {`input[type="radio" i]`},
+	"region":        {"section[aria-label]", "section[aria-labelledby]"},
+	"row":           {"tr"},
+	"searchbox":     {`input[type="search" i]`},
+	"separator":     {"hr"},
+	"slider":        {`input[type="range" i]`},
+	"spinbutton":    {`input[type="number" i]`},
+	"table":         {"table"},
+	"textbox":       {"input:not([type])", `input[type="text" i]`, `input[type="email" i]`, `input[type="tel" i]`, `input[type="url" i]`, "textarea"},
+}
+
+// roleSelector returns the selector of the elements with a class attribute
+// that have one of the given ARIA roles, either explicitly by their role
+// attribute or implicitly by their element type.
+func roleSelector(roles []string) string {
+	var selectors []string
+	for _, role := range roles {
+		role = strings.ToLower(strings.TrimSpace(role))
+		if role == "" {
+			continue
+		}
+		selectors = append(selectors, "[class][role~="+strconv.Quote(role)+" i]")
+		for _, implicit := range implicitRoles[role] {
+			selectors = append(selectors, implicit+"[class]:not([role])")
+		}
+	}
+	return strings.Join(selectors, ", ")
+}
+
+// classSelector returns the selector of the elements whose classes are
+// extracted (see [WithRoleFilter]).
+func (f *Finder) classSelector() string {
+	if f.roleSelector != "" {
+		return f.roleSelector
+	}
+	return "[class]"
+}
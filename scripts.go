@@ -0,0 +1,107 @@
+package siteperf
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-rod/rod"
+)
+
+var markupClassRE = regexp.MustCompile("(?i)\\bclass\\s*=\\s*(?:\"([^\"]*)\"|'([^']*)'|([^\\s\"'=<>`]+))")
+
+// extractScriptMarkupClasses returns the classes of the markup that is
+// embedded within the <script> elements of the configured types, mapped to the
+// number of class attributes they were found in.
+func (f *Finder) extractScriptMarkupClasses(page *rod.Page) (map[string]int, error) {
+	res, err := page.Eval(`(types) => Array.from(document.querySelectorAll("script"))
+		.filter((el) => types.includes((el.getAttribute("type") || "").trim().toLowerCase()))
+		.map((el) => el.textContent)`, f.scriptMarkupTypes)
+	if err != nil {
+		return nil, fmt.Errorf("get contents of <script> elements: %w", err)
+	}
+
+	var scripts []string
+	if err := json.Unmarshal([]byte(res.Value.JSON("", "")), &scripts); err != nil {
+		return nil, fmt.Errorf("decode contents of <script> elements: %w", err)
+	}
+
+	found := make(map[string]int)
+	for _, script := range scripts {
+		for _, markup := range scriptMarkup(script) {
+			for class, count := range markupClasses(markup) {
+				if f.dropInvalidClasses && !isValidClass(class) {
+					continue
+				}
+				found[class] += count
+			}
+		}
+	}
+
+	return found, nil
+}
+
+// scriptMarkup returns the markup candidates within the contents of a script.
+// If the contents are valid JSON, every string value is a candidate, so that
+// markup with JSON-escaped quotes is found. Otherwise, the raw contents are the
+// only candidate.
+func scriptMarkup(script string) []string {
+	var v any
+	if err := json.Unmarshal([]byte(script), &v); err != nil {
+		return []string{script}
+	}
+
+	var out []string
+	var walk func(any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case string:
+			if strings.Contains(v, "class") {
+				out = append(out, v)
+			}
+		case []any:
+			for _, e := range v {
+				walk(e)
+			}
+		case map[string]any:
+			for _, e := range v {
+				walk(e)
+			}
+		}
+	}
+	walk(v)
+
+	return out
+}
+
+// markupClasses returns the classes of all class attributes within the given
+// markup, mapped to the number of attributes they were found in.
+func markupClasses(markup string) map[string]int {
+	found := make(map[string]int)
+	for _, match := range markupClassRE.FindAllStringSubmatch(markup, -1) {
+		for _, class := range splitClassList(match[1] + match[2] + match[3]) {
+			found[class]++
+		}
+	}
+	return found
+}
+
+// mergeUsedClasses adds the given class counts to the used classes.
+func mergeUsedClasses(classes []usedClass, extra map[string]int) []usedClass {
+	index := make(map[string]int, len(classes))
+	for i, class := range classes {
+		index[class.class] = i
+	}
+
+	for class, count := range extra {
+		if i, ok := index[class]; ok {
+			classes[i].count += count
+			continue
+		}
+		index[class] = len(classes)
+		classes = append(classes, usedClass{class: class, count: count})
+	}
+
+	return classes
+}
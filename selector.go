@@ -0,0 +1,204 @@
+package siteperf
+
+import "strings"
+
+// complexSelector is a parsed selector, i.e. a sequence of compound selectors
+// separated by combinators.
+type complexSelector struct {
+	compounds []compoundSelector
+
+	// combinators contains the combinators between the compound selectors:
+	// ' ' (descendant), '>' (child), '+' (next sibling), or '~' (subsequent
+	// sibling). combinators[i] is the combinator between compounds[i] and
+	// compounds[i+1].
+	combinators []byte
+}
+
+// compoundSelector is a sequence of simple selectors that all apply to the
+// same element, e.g. "button.btn:hover".
+type compoundSelector struct {
+	tag     string
+	ids     []string
+	classes []string
+	attrs   []attrSelector
+	pseudos []pseudoSelector
+}
+
+// attrSelector is an attribute selector like [aria-expanded="true"].
+type attrSelector struct {
+	name     string
+	operator string
+	value    string
+}
+
+// pseudoSelector is a pseudo-class or pseudo-element.
+type pseudoSelector struct {
+	// name is the lowercased name without leading colons.
+	name    string
+	element bool
+
+	// args contains the raw arguments of a functional pseudo-class.
+	args string
+
+	// selectors contains the parsed arguments of pseudo-classes that take a
+	// selector list, like :not() or :is().
+	selectors []complexSelector
+}
+
+// logicalPseudoClasses are the functional pseudo-classes whose arguments are
+// selector lists.
+var logicalPseudoClasses = map[string]bool{
+	"not":          true,
+	"is":           true,
+	"where":        true,
+	"has":          true,
+	"matches":      true,
+	"-webkit-any":  true,
+	"-moz-any":     true,
+	"host":         true,
+	"host-context": true,
+	"slotted":      true,
+}
+
+// logical reports whether the pseudo-class combines other selectors rather
+// than selecting an element state.
+func (ps pseudoSelector) logical() bool {
+	return logicalPseudoClasses[ps.name]
+}
+
+// parseSelectorList parses a comma-separated list of selectors.
+func parseSelectorList(s string) []complexSelector {
+	parts := splitTopLevel(s, ',')
+	out := make([]complexSelector, 0, len(parts))
+	for _, part := range parts {
+		if sel := parseSelector(part); len(sel.compounds) > 0 {
+			out = append(out, sel)
+		}
+	}
+	return out
+}
+
+// parseSelector parses a single complex selector. Unknown syntax is skipped.
+func parseSelector(s string) complexSelector {
+	var (
+		sel        complexSelector
+		current    compoundSelector
+		hasCurrent bool
+		pending    byte
+	)
+
+	flush := func() {
+		if !hasCurrent {
+			return
+		}
+		if len(sel.compounds) > 0 {
+			comb := pending
+			if comb == 0 {
+				comb = ' '
+			}
+			sel.combinators = append(sel.combinators, comb)
+		}
+		sel.compounds = append(sel.compounds, current)
+		current = compoundSelector{}
+		hasCurrent = false
+		pending = 0
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case isSpace(c):
+			flush()
+			i++
+		case c == '>' || c == '+' || c == '~':
+			flush()
+			pending = c
+			i++
+		case c == '.':
+			name, next := readIdent(s, i+1)
+			if name != "" {
+				current.classes = append(current.classes, name)
+				hasCurrent = true
+			}
+			i = max(next, i+1)
+		case c == '#':
+			name, next := readIdent(s, i+1)
+			if name != "" {
+				current.ids = append(current.ids, name)
+				hasCurrent = true
+			}
+			i = max(next, i+1)
+		case c == '[':
+			end := scanUntil(s, i+1, len(s), "]")
+			current.attrs = append(current.attrs, parseAttrSelector(s[i+1:min(end, len(s))]))
+			hasCurrent = true
+			i = end + 1
+		case c == ':':
+			ps := pseudoSelector{}
+			i++
+			if i < len(s) && s[i] == ':' {
+				ps.element = true
+				i++
+			}
+			var name string
+			name, i = readIdent(s, i)
+			ps.name = strings.ToLower(name)
+			if i < len(s) && s[i] == '(' {
+				end := scanUntil(s, i+1, len(s), ")")
+				ps.args = s[i+1 : min(end, len(s))]
+				if ps.logical() {
+					ps.selectors = parseSelectorList(ps.args)
+				}
+				i = end + 1
+			}
+			current.pseudos = append(current.pseudos, ps)
+			hasCurrent = true
+		case c == '*' || c == '&':
+			current.tag = string(c)
+			hasCurrent = true
+			i++
+		case isIdentChar(c) || c == '\\':
+			name, next := readIdent(s, i)
+			current.tag = strings.ToLower(name)
+			hasCurrent = true
+			i = max(next, i+1)
+		default:
+			i++
+		}
+	}
+	flush()
+
+	return sel
+}
+
+func parseAttrSelector(raw string) attrSelector {
+	raw = strings.TrimSpace(raw)
+
+	opStart := strings.IndexAny(raw, "~|^$*=")
+	if opStart < 0 {
+		return attrSelector{name: strings.ToLower(raw)}
+	}
+
+	opEnd := strings.IndexByte(raw[opStart:], '=')
+	if opEnd < 0 {
+		return attrSelector{name: strings.ToLower(raw)}
+	}
+	opEnd += opStart + 1
+
+	attr := attrSelector{
+		name:     strings.ToLower(strings.TrimSpace(raw[:opStart])),
+		operator: raw[opStart:opEnd],
+	}
+
+	value := strings.TrimSpace(raw[opEnd:])
+	// Strip the case-sensitivity flag ([attr="value" i]).
+	if n := len(value); n > 2 && isSpace(value[n-2]) && strings.ContainsRune("iIsS", rune(value[n-1])) {
+		value = strings.TrimSpace(value[:n-2])
+	}
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		value = value[1 : len(value)-1]
+	}
+	attr.value = value
+
+	return attr
+}
@@ -0,0 +1,124 @@
+package siteperf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// maxSitemapDepth is the maximum nesting depth of sitemap index files.
+const maxSitemapDepth = 3
+
+// maxSitemapSize is the maximum size of a decompressed sitemap if the size of
+// responses is not limited (see [WithMaxResponseSize]). The sitemaps protocol
+// limits sitemaps to 50 MB.
+const maxSitemapSize = 50 << 20
+
+// sitemap is a sitemap or sitemap index file as specified by sitemaps.org.
+type sitemap struct {
+	URLs []struct {
+		Loc string `xml:"loc"`
+	} `xml:"url"`
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+// SitemapURLs fetches the sitemap at the given URL and returns the URLs of
+// the pages it lists, sorted and without duplicates. If sitemapURL is empty,
+// the sitemap is fetched from "/sitemap.xml" of the root URL. Sitemap index
+// files are followed, and gzip-compressed sitemaps are decompressed. The
+// decompressed size of a sitemap is limited like the size of responses (see
+// [WithMaxResponseSize]), or to 50 MB if responses are not limited, so that a
+// small compressed sitemap cannot expand to an arbitrary amount of memory.
+func (f *Finder) SitemapURLs(ctx context.Context, sitemapURL string) ([]string, error) {
+	if sitemapURL == "" {
+		sitemapURL = f.rootURL.ResolveReference(&url.URL{Path: "/sitemap.xml"}).String()
+	}
+
+	urls, err := f.sitemapURLs(ctx, sitemapURL, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	urls = unique(urls)
+	slices.Sort(urls)
+
+	return urls, nil
+}
+
+func (f *Finder) sitemapURLs(ctx context.Context, sitemapURL string, depth int) ([]string, error) {
+	body, err := f.fetch(ctx, sitemapURL)
+	if err != nil {
+		return nil, fmt.Errorf("fetch sitemap %q: %w", sitemapURL, err)
+	}
+
+	sm, err := parseSitemap(body, f.maxSitemapSize())
+	if err != nil {
+		return nil, fmt.Errorf("parse sitemap %q: %w", sitemapURL, err)
+	}
+
+	var out []string
+	for _, u := range sm.URLs {
+		if loc := strings.TrimSpace(u.Loc); loc != "" {
+			out = append(out, loc)
+		}
+	}
+
+	if depth >= maxSitemapDepth {
+		return out, nil
+	}
+
+	for _, s := range sm.Sitemaps {
+		loc := strings.TrimSpace(s.Loc)
+		if loc == "" {
+			continue
+		}
+		urls, err := f.sitemapURLs(ctx, loc, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, urls...)
+	}
+
+	return out, nil
+}
+
+// maxSitemapSize returns the maximum decompressed size of a sitemap.
+func (f *Finder) maxSitemapSize() int64 {
+	if f.maxResponseSize > 0 {
+		return f.maxResponseSize
+	}
+	return maxSitemapSize
+}
+
+// parseSitemap parses the given sitemap, which is decompressed first if it is
+// compressed. Decompressing more than maxSize bytes fails with
+// [ErrResponseTooLarge].
+func parseSitemap(body []byte, maxSize int64) (sitemap, error) {
+	var r io.Reader = bytes.NewReader(body)
+
+	// Compressed sitemaps are not necessarily served with a Content-Encoding
+	// header, so they are detected by the gzip magic number.
+	if bytes.HasPrefix(body, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return sitemap{}, fmt.Errorf("decompress: %w", err)
+		}
+		defer gz.Close()
+		r = limitBody(gz, maxSize)
+	}
+
+	var sm sitemap
+	if err := xml.NewDecoder(r).Decode(&sm); err != nil {
+		return sitemap{}, err
+	}
+
+	return sm, nil
+}
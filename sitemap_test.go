@@ -0,0 +1,79 @@
+package siteperf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// gzipSitemap returns the compressed sitemap of the given URLs, padded with
+// the given number of spaces. Spaces compress to almost nothing, so a large
+// padding makes a sitemap that expands far beyond its compressed size.
+func gzipSitemap(t *testing.T, padding int, urls ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`))
+	for _, u := range urls {
+		gz.Write([]byte("<url><loc>" + u + "</loc></url>"))
+	}
+	gz.Write([]byte(strings.Repeat(" ", padding)))
+	gz.Write([]byte(`</urlset>`))
+	if err := gz.Close(); err != nil {
+		t.Fatalf("compress sitemap: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestFinder_SitemapURLs_compressed(t *testing.T) {
+	small := gzipSitemap(t, 0, "https://example.com/", "https://example.com/about")
+	bomb := gzipSitemap(t, 8<<20, "https://example.com/")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sitemap.xml.gz", func(w http.ResponseWriter, r *http.Request) { w.Write(small) })
+	mux.HandleFunc("/bomb.xml.gz", func(w http.ResponseWriter, r *http.Request) { w.Write(bomb) })
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	// The compressed bomb is far smaller than the limit, but expands beyond
+	// it.
+	const limit = 1 << 20
+	if len(bomb) >= limit {
+		t.Fatalf("compressed sitemap has %d bytes, want less than %d", len(bomb), limit)
+	}
+
+	f, err := New(srv.URL, 0, WithMaxResponseSize(limit))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	urls, err := f.SitemapURLs(context.Background(), srv.URL+"/sitemap.xml.gz")
+	if err != nil {
+		t.Fatalf("SitemapURLs() failed: %v", err)
+	}
+	if want := []string{"https://example.com/", "https://example.com/about"}; !slices.Equal(urls, want) {
+		t.Errorf("SitemapURLs() = %q, want %q", urls, want)
+	}
+
+	if _, err := f.SitemapURLs(context.Background(), srv.URL+"/bomb.xml.gz"); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("SitemapURLs() of oversized sitemap returned %v, want %v", err, ErrResponseTooLarge)
+	}
+}
+
+func TestParseSitemap_defaultLimit(t *testing.T) {
+	f, err := New("https://example.com", 0)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	bomb := gzipSitemap(t, maxSitemapSize+1, "https://example.com/")
+	if _, err := parseSitemap(bomb, f.maxSitemapSize()); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("parseSitemap() returned %v, want %v", err, ErrResponseTooLarge)
+	}
+}
@@ -0,0 +1,140 @@
+package siteperf
+
+import (
+	"cmp"
+	"strings"
+)
+
+// Specificity is the specificity of a selector, as defined by the Selectors
+// specification.
+type Specificity struct {
+	// IDs is the number of ID selectors.
+	IDs int `json:"ids"`
+
+	// Classes is the number of class selectors, attribute selectors, and
+	// pseudo-classes.
+	Classes int `json:"classes"`
+
+	// Types is the number of type selectors and pseudo-elements.
+	Types int `json:"types"`
+}
+
+// Score returns the specificity as a single number that orders selectors like
+// their specificity does, i.e. IDs*10000 + Classes*100 + Types. It is only
+// accurate as long as there are fewer than 100 selectors of each category.
+func (s Specificity) Score() int {
+	return s.IDs*10000 + s.Classes*100 + s.Types
+}
+
+// Compare returns -1, 0, or +1 depending on whether s is less specific than,
+// as specific as, or more specific than o.
+func (s Specificity) Compare(o Specificity) int {
+	if c := cmp.Compare(s.IDs, o.IDs); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(s.Classes, o.Classes); c != 0 {
+		return c
+	}
+	return cmp.Compare(s.Types, o.Types)
+}
+
+func (s Specificity) add(o Specificity) Specificity {
+	return Specificity{IDs: s.IDs + o.IDs, Classes: s.Classes + o.Classes, Types: s.Types + o.Types}
+}
+
+// ExtractSelectors returns the selectors of all style rules of the given CSS,
+// with nested selectors resolved, in the order in which they appear and
+// without duplicates. Selector lists are split into their selectors.
+func ExtractSelectors(css string) []string {
+	sheet := parseStylesheet(css)
+
+	var out []string
+	for _, rule := range sheet.rules {
+		for _, raw := range rule.selectors {
+			for _, sel := range splitTopLevel(raw, ',') {
+				if sel = strings.TrimSpace(sel); sel != "" {
+					out = append(out, sel)
+				}
+			}
+		}
+	}
+
+	return unique(out)
+}
+
+// SelectorSpecificity returns the specificity score (see [Specificity.Score])
+// of every selector of the given CSS (see [ExtractSelectors]). Selectors with
+// a high specificity are hard to override and often a sign of CSS that is
+// hard to maintain.
+func SelectorSpecificity(css string) map[string]int {
+	out := make(map[string]int)
+	for _, sel := range ExtractSelectors(css) {
+		out[sel] = SelectorSpecificityOf(sel).Score()
+	}
+	return out
+}
+
+// SelectorSpecificityOf returns the specificity of a single selector. If the
+// selector is a selector list, the specificity of its most specific selector
+// is returned.
+func SelectorSpecificityOf(selector string) Specificity {
+	return maxSpecificity(parseSelectorList(selector))
+}
+
+func maxSpecificity(list []complexSelector) Specificity {
+	var out Specificity
+	for _, sel := range list {
+		if s := sel.specificity(); s.Compare(out) > 0 {
+			out = s
+		}
+	}
+	return out
+}
+
+func (sel complexSelector) specificity() Specificity {
+	var out Specificity
+	for _, compound := range sel.compounds {
+		out = out.add(compound.specificity())
+	}
+	return out
+}
+
+// legacyPseudoElements are the pseudo-elements that may be written with a
+// single colon, like pseudo-classes.
+var legacyPseudoElements = map[string]bool{
+	"before":       true,
+	"after":        true,
+	"first-line":   true,
+	"first-letter": true,
+}
+
+func (c compoundSelector) specificity() Specificity {
+	out := Specificity{
+		IDs:     len(c.ids),
+		Classes: len(c.classes) + len(c.attrs),
+	}
+	if c.tag != "" && c.tag != "*" {
+		out.Types++
+	}
+
+	for _, ps := range c.pseudos {
+		switch {
+		case ps.element || legacyPseudoElements[ps.name]:
+			out.Types++
+		case ps.name == "where":
+			// :where() never adds specificity.
+		case ps.name == "is" || ps.name == "not" || ps.name == "has" || ps.name == "matches" ||
+			ps.name == "-webkit-any" || ps.name == "-moz-any":
+			out = out.add(maxSpecificity(ps.selectors))
+		case ps.logical():
+			// :host(), :host-context(), and ::slotted() count as a
+			// pseudo-class plus their argument.
+			out.Classes++
+			out = out.add(maxSpecificity(ps.selectors))
+		default:
+			out.Classes++
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,127 @@
+//go:build sqlite
+
+package siteperf
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS crawls (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	root_url    TEXT NOT NULL,
+	started_at  TEXT NOT NULL,
+	finished_at TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS classes (
+	crawl_id  INTEGER NOT NULL REFERENCES crawls(id) ON DELETE CASCADE,
+	name      TEXT NOT NULL,
+	unused    INTEGER NOT NULL,
+	undefined INTEGER NOT NULL,
+	root_only INTEGER NOT NULL,
+	PRIMARY KEY (crawl_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS pages (
+	crawl_id INTEGER NOT NULL REFERENCES crawls(id) ON DELETE CASCADE,
+	url      TEXT NOT NULL,
+	PRIMARY KEY (crawl_id, url)
+);
+
+CREATE TABLE IF NOT EXISTS usage (
+	crawl_id INTEGER NOT NULL REFERENCES crawls(id) ON DELETE CASCADE,
+	class    TEXT NOT NULL,
+	count    INTEGER NOT NULL,
+	PRIMARY KEY (crawl_id, class)
+);
+`
+
+// WriteSQLite stores the audit as a new crawl in the SQLite database at the
+// given path, creating the database and its tables if they do not exist. Every
+// call adds a row to the "crawls" table, so that the results of successive
+// audits can be compared using SQL. The "classes" table contains the audited
+// and undefined classes of each crawl along with their status, the "pages"
+// table contains the crawled pages from the metadata, and the "usage" table
+// contains the usage count of every class found on the crawled pages.
+//
+// WriteSQLite is only available if siteperf is built with the "sqlite" build
+// tag, which avoids the dependency on the SQLite driver by default.
+func WriteSQLite(path string, audit Audit, meta CrawlMeta) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("open database: %w", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		return fmt.Errorf("create tables: %w", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		`INSERT INTO crawls (root_url, started_at, finished_at) VALUES (?, ?, ?)`,
+		meta.RootURL, meta.StartedAt.UTC().Format(time.RFC3339Nano), meta.FinishedAt.UTC().Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		return fmt.Errorf("insert crawl: %w", err)
+	}
+
+	crawlID, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("get crawl id: %w", err)
+	}
+
+	type classStatus struct{ unused, undefined, rootOnly bool }
+	classes := make(map[string]classStatus)
+	for class := range audit.Usage {
+		classes[class] = classStatus{}
+	}
+	for _, class := range audit.Unused {
+		s := classes[class]
+		s.unused = true
+		classes[class] = s
+	}
+	for _, class := range audit.Undefined {
+		s := classes[class]
+		s.undefined = true
+		classes[class] = s
+	}
+	for _, class := range audit.RootOnly {
+		s := classes[class]
+		s.rootOnly = true
+		classes[class] = s
+	}
+
+	for class, s := range classes {
+		if _, err := tx.Exec(
+			`INSERT INTO classes (crawl_id, name, unused, undefined, root_only) VALUES (?, ?, ?, ?, ?)`,
+			crawlID, class, s.unused, s.undefined, s.rootOnly,
+		); err != nil {
+			return fmt.Errorf("insert class %q: %w", class, err)
+		}
+	}
+
+	for _, page := range unique(meta.Pages) {
+		if _, err := tx.Exec(`INSERT INTO pages (crawl_id, url) VALUES (?, ?)`, crawlID, page); err != nil {
+			return fmt.Errorf("insert page %q: %w", page, err)
+		}
+	}
+
+	for class, count := range audit.Usage {
+		if _, err := tx.Exec(`INSERT INTO usage (crawl_id, class, count) VALUES (?, ?, ?)`, crawlID, class, count); err != nil {
+			return fmt.Errorf("insert usage of %q: %w", class, err)
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,186 @@
+package siteperf
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CrawlStats is a snapshot of the progress of the crawls of a [Finder].
+type CrawlStats struct {
+	// Running is the number of crawls that are currently running.
+	Running int `json:"running"`
+
+	// Queued is the number of discovered pages that are waiting to be
+	// visited.
+	Queued int `json:"queued"`
+
+	// Visited is the number of pages that were visited successfully.
+	Visited int `json:"visited"`
+
+	// Failed is the number of pages that could not be visited.
+	Failed int `json:"failed"`
+
+	// StartedAt is the time at which the counters were last reset, i.e. the
+	// time at which a crawl was started while no other crawl was running.
+	StartedAt time.Time `json:"startedAt"`
+
+	// Elapsed is the time since StartedAt.
+	Elapsed time.Duration `json:"elapsed"`
+
+	// PagesPerSecond is the number of visited and failed pages per second
+	// since StartedAt.
+	PagesPerSecond float64 `json:"pagesPerSecond"`
+
+	// SampledPages is the number of visited pages whose classes are based on
+	// a random sample of their elements (see [WithElementSample]). If it is
+	// not 0, the usage counts are approximate, and classes that are only used
+	// on few elements of these pages may be reported as unused. It is only
+	// set in the stats of a finished crawl.
+	SampledPages int `json:"sampledPages,omitempty"`
+
+	// Incomplete reports whether the crawl stopped before all reachable pages
+	// were visited, and IncompleteReason why. If a crawl is incomplete, the
+	// classes of the unvisited pages are missing, so classes may be reported
+	// as unused although they are used. Both are only set in the stats of a
+	// finished crawl (see [Finder.FindUnusedWithStats]).
+	Incomplete       bool             `json:"incomplete,omitempty"`
+	IncompleteReason IncompleteReason `json:"incompleteReason,omitempty"`
+}
+
+// IncompleteReason is the reason why a crawl stopped before all reachable
+// pages were visited.
+type IncompleteReason string
+
+const (
+	// IncompletePageLimit means that the page limit was reached.
+	IncompletePageLimit = IncompleteReason("page-limit")
+
+	// IncompleteMaxDuration means that the maximum duration of the crawl was
+	// exceeded (see [WithMaxDuration]).
+	IncompleteMaxDuration = IncompleteReason("max-duration")
+
+	// IncompleteCanceled means that the context of the crawl was canceled.
+	IncompleteCanceled = IncompleteReason("canceled")
+
+	// IncompleteStopped means that the consumer of the crawl stopped it, e.g.
+	// by breaking out of the loop over [Finder.Pages].
+	IncompleteStopped = IncompleteReason("stopped")
+
+	// IncompleteExtractTimeout means that the extraction budget of the crawl
+	// was exhausted (see [WithExtractTimeout]). All reachable pages were
+	// visited, but the classes of some of them were not extracted.
+	IncompleteExtractTimeout = IncompleteReason("extract-timeout")
+)
+
+// errMaxDuration is the cause of the context cancellation of a crawl that
+// exceeded its maximum duration.
+var errMaxDuration = errors.New("maximum crawl duration exceeded")
+
+// FindUnusedWithStats works like [Finder.FindUnused], but also returns the
+// stats of the crawl. If the stats report the crawl as incomplete, the
+// returned classes may include classes that are only used on pages that were
+// not visited, so they should not be removed without further checks.
+func (f *Finder) FindUnusedWithStats(ctx context.Context, classes []string) ([]string, CrawlStats, error) {
+	result, err := f.crawl(ctx)
+	if err != nil {
+		return nil, CrawlStats{}, fmt.Errorf("find used classes: %w", err)
+	}
+	return f.FindUnusedFromUsed(result.usedCounts(), classes), result.summary.stats(), nil
+}
+
+// crawlCounters are the live counters of the crawls of a Finder. They are
+// reset when a crawl starts while no other crawl is running.
+type crawlCounters struct {
+	mux       sync.Mutex
+	running   int
+	startedAt time.Time
+
+	queued  atomic.Int64
+	visited atomic.Int64
+	failed  atomic.Int64
+}
+
+// start registers a starting crawl and returns a function that must be called
+// when the crawl is done.
+func (c *crawlCounters) start() func() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if c.running == 0 {
+		c.clear()
+		c.startedAt = time.Now()
+	}
+	c.running++
+
+	return func() {
+		c.mux.Lock()
+		defer c.mux.Unlock()
+		c.running--
+	}
+}
+
+// reset clears the counters, unless a crawl is running.
+func (c *crawlCounters) reset() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if c.running == 0 {
+		c.clear()
+	}
+}
+
+// clear zeroes the counters. c.mux must be held.
+func (c *crawlCounters) clear() {
+	c.startedAt = time.Time{}
+	c.queued.Store(0)
+	c.visited.Store(0)
+	c.failed.Store(0)
+}
+
+func (c *crawlCounters) snapshot() CrawlStats {
+	c.mux.Lock()
+	stats := CrawlStats{
+		Running:   c.running,
+		StartedAt: c.startedAt,
+	}
+	c.mux.Unlock()
+
+	stats.Queued = int(c.queued.Load())
+	stats.Visited = int(c.visited.Load())
+	stats.Failed = int(c.failed.Load())
+
+	if !stats.StartedAt.IsZero() {
+		stats.Elapsed = time.Since(stats.StartedAt)
+		if secs := stats.Elapsed.Seconds(); secs > 0 {
+			stats.PagesPerSecond = float64(stats.Visited+stats.Failed) / secs
+		}
+	}
+
+	return stats
+}
+
+// Stats returns a snapshot of the live counters of the crawls of the Finder.
+// It is safe to call Stats while a crawl is running.
+func (f *Finder) Stats() CrawlStats {
+	return f.counters.snapshot()
+}
+
+// DebugHandler returns an [http.Handler] that responds with the current
+// [CrawlStats] of the Finder as JSON. It can be mounted on a debug server of
+// a service that embeds long-running crawls, e.g. at "/debug/siteperf".
+func (f *Finder) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(f.Stats()); err != nil {
+			f.log.Warn("Failed to write debug stats", "err", err)
+		}
+	})
+}
@@ -0,0 +1,33 @@
+package siteperf
+
+import (
+	"slices"
+
+	"github.com/go-rod/rod"
+)
+
+// responseStatus returns the HTTP status code of the navigation response of
+// the page, or 0 if it is not known, e.g. for non-HTTP URLs.
+func responseStatus(page *rod.Page) (int, error) {
+	res, err := page.Eval(`() => {
+		const [nav] = performance.getEntriesByType("navigation")
+		return nav && nav.responseStatus ? nav.responseStatus : 0
+	}`)
+	if err != nil {
+		return 0, err
+	}
+	return res.Value.Int(), nil
+}
+
+// acceptStatus reports whether pages with the given status code are crawled.
+// By default, only 2xx status codes are accepted. Unknown status codes (0)
+// are always accepted.
+func (f *Finder) acceptStatus(code int) bool {
+	if code == 0 {
+		return true
+	}
+	if f.acceptedStatus != nil {
+		return slices.Contains(f.acceptedStatus, code)
+	}
+	return code >= 200 && code <= 299
+}
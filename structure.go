@@ -0,0 +1,64 @@
+package siteperf
+
+import (
+	"sync"
+
+	"github.com/go-rod/rod"
+)
+
+// structureSet contains the structure hashes of the pages of a crawl (see
+// [WithDedupByStructure]). A structureSet is safe for concurrent use.
+type structureSet struct {
+	mux    sync.Mutex
+	hashes map[string]bool
+}
+
+// newStructureSet returns a new structureSet, or nil if pages are not
+// deduplicated by structure.
+func (f *Finder) newStructureSet() *structureSet {
+	if !f.dedupByStructure {
+		return nil
+	}
+	return &structureSet{hashes: make(map[string]bool)}
+}
+
+// add adds the structure hash of the page to the set and reports whether it
+// was already contained.
+func (s *structureSet) add(page *rod.Page) (bool, error) {
+	res, err := page.Eval(structureHashScript)
+	if err != nil {
+		return false, err
+	}
+	hash := res.Value.Str()
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.hashes[hash] {
+		return true, nil
+	}
+	s.hashes[hash] = true
+
+	return false, nil
+}
+
+// structureHashScript hashes the tag names and class attributes of all
+// elements with a class attribute, in document order, using two 32-bit FNV-1a
+// hashes with different offset bases to make collisions unlikely.
+const structureHashScript = `() => {
+	let a = 0x811c9dc5, b = 0x050c5d1f
+	const add = (s) => {
+		for (let i = 0; i < s.length; i++) {
+			const c = s.charCodeAt(i)
+			a = Math.imul(a ^ c, 0x01000193) >>> 0
+			b = Math.imul(b ^ c, 0x01000193) >>> 0
+		}
+	}
+	for (const el of document.querySelectorAll("[class]")) {
+		add(el.tagName)
+		add("\u0000")
+		add(el.getAttribute("class"))
+		add("\u0001")
+	}
+	return a.toString(16) + b.toString(16)
+}`
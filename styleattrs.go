@@ -0,0 +1,90 @@
+package siteperf
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/go-rod/rod"
+)
+
+// InlineStyles crawls the website of the Finder and counts the properties that
+// are set by the inline style attributes of the elements on the crawled pages,
+// e.g. {"display": 12, "margin-top": 3}. A property is counted once for every
+// element whose style attribute sets it. Inline styles cannot be reused or
+// overridden by stylesheets, so frequently set properties are candidates for
+// utility classes or component styles.
+//
+// Property names are lowercased, except for custom properties like "--gap",
+// which are case-sensitive. Style attributes without any declaration are not
+// counted.
+func (f *Finder) InlineStyles(ctx context.Context) (map[string]int, error) {
+	var mux sync.Mutex
+	counts := make(map[string]int)
+
+	_, err := f.crawl(ctx, func(ctx context.Context, page *rod.Page, result *pageResult) error {
+		elements, err := extractAttributes(page, "[style]", "style")
+		if err != nil {
+			return fmt.Errorf("get elements with style attribute: %w", err)
+		}
+
+		mux.Lock()
+		defer mux.Unlock()
+		for _, el := range elements {
+			for _, prop := range styleProperties(el.Attrs["style"]) {
+				counts[prop]++
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("find inline styles: %w", err)
+	}
+
+	return counts, nil
+}
+
+// styleProperties returns the distinct property names of the declarations
+// within a style attribute. Semicolons within strings and parentheses, like
+// in url("data:image/png;base64,..."), do not separate declarations.
+func styleProperties(style string) []string {
+	blanked, _ := blankStrings(style)
+
+	var (
+		props []string
+		depth int
+		start int
+	)
+	add := func(decl string) {
+		name, _, ok := strings.Cut(decl, ":")
+		if name = strings.TrimSpace(name); !ok || name == "" {
+			return
+		}
+		if !strings.HasPrefix(name, "--") {
+			name = strings.ToLower(name)
+		}
+		if !slices.Contains(props, name) {
+			props = append(props, name)
+		}
+	}
+
+	for i := 0; i < len(blanked); i++ {
+		switch blanked[i] {
+		case '(':
+			depth++
+		case ')':
+			depth = max(depth-1, 0)
+		case ';':
+			if depth == 0 {
+				add(blanked[start:i])
+				start = i + 1
+			}
+		}
+	}
+	add(blanked[start:])
+
+	return props
+}
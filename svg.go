@@ -0,0 +1,46 @@
+package siteperf
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-rod/rod"
+)
+
+// extractSVGUseClasses returns the ids of the SVG symbols that are referenced
+// by the <use> elements of the page, mapped to the number of <use> elements
+// that reference them (see [WithSVGUseReferences]). Both the href and the
+// legacy xlink:href attribute are read; href takes precedence if both are set.
+func (f *Finder) extractSVGUseClasses(page *rod.Page) (map[string]int, error) {
+	elements, err := extractAttributes(page, "use", "href", "xlink:href")
+	if err != nil {
+		return nil, fmt.Errorf("get <use> elements: %w", err)
+	}
+
+	found := make(map[string]int)
+	for _, el := range elements {
+		ref, ok := el.Attrs["href"]
+		if !ok {
+			ref = el.Attrs["xlink:href"]
+		}
+		id := svgSymbolID(ref)
+		if id == "" || (f.dropInvalidClasses && !isValidClass(id)) {
+			continue
+		}
+		found[id]++
+	}
+
+	return found, nil
+}
+
+// svgSymbolID returns the fragment of a <use> reference, which is the id of the
+// referenced symbol. References to symbols within the same document
+// ("#icon-foo") and within external sprites ("/sprite.svg#icon-foo") are
+// supported. An empty string is returned if the reference has no fragment.
+func svgSymbolID(ref string) string {
+	_, id, ok := strings.Cut(strings.TrimSpace(ref), "#")
+	if !ok {
+		return ""
+	}
+	return id
+}
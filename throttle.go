@@ -0,0 +1,61 @@
+package siteperf
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// throttle spaces out page visits across all workers of a crawl so that at
+// most one page is visited per delay.
+type throttle struct {
+	mux   sync.Mutex
+	delay time.Duration
+	next  time.Time
+}
+
+func newThrottle(delay time.Duration) *throttle {
+	return &throttle{delay: delay}
+}
+
+// wait blocks until the next visit is allowed or the context is canceled.
+func (t *throttle) wait(ctx context.Context) error {
+	if t.delay <= 0 {
+		return nil
+	}
+
+	t.mux.Lock()
+	now := time.Now()
+	at := t.next
+	if at.Before(now) {
+		at = now
+	}
+	t.next = at.Add(t.delay)
+	t.mux.Unlock()
+
+	timer := time.NewTimer(time.Until(at))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// acquireNavigation blocks until a page may start navigating or the context
+// is canceled (see [WithMaxConcurrentNavigations]). The returned function
+// releases the navigation slot. It may be called multiple times.
+func (f *Finder) acquireNavigation(ctx context.Context) (func(), error) {
+	if f.navigations == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case f.navigations <- struct{}{}:
+		return sync.OnceFunc(func() { <-f.navigations }), nil
+	}
+}
@@ -0,0 +1,36 @@
+package siteperf
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// tracerName is the name of the tracer that instruments crawls.
+const tracerName = "github.com/bounoable/siteperf"
+
+// tracer returns the tracer of the configured tracer provider (see
+// [WithTracerProvider]), or a tracer that records nothing.
+func (f *Finder) tracer() trace.Tracer {
+	if f.tracerProvider == nil {
+		return noop.NewTracerProvider().Tracer(tracerName)
+	}
+	return f.tracerProvider.Tracer(tracerName)
+}
+
+// startSpan starts a span of the crawl with the given name and attributes.
+func (f *Finder) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return f.tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan records err, if any, on the span and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
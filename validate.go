@@ -0,0 +1,130 @@
+package siteperf
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// cssReportSampleSize is the maximum number of classes in the sample of a
+// [CSSReport].
+const cssReportSampleSize = 10
+
+// CSSReport is the result of validating a CSS file without crawling a website
+// (see [ValidateCSS]).
+type CSSReport struct {
+	// Path is the path of the validated file.
+	Path string `json:"path"`
+
+	// Classes is the number of distinct classes extracted from the file.
+	Classes int `json:"classes"`
+
+	// Sample contains the first classes extracted from the file, in sorted
+	// order, to confirm at a glance that the right file was validated.
+	Sample []string `json:"sample"`
+
+	// Rules is the number of style rules of the file.
+	Rules int `json:"rules"`
+
+	// InvalidTokens contains the class-like tokens of selectors that are not
+	// valid class names and are therefore not extracted, like ".2xl" or
+	// ".-mt-2", sorted and without duplicates. Escaping such classes in the
+	// CSS, like ".\32xl", makes them extractable.
+	InvalidTokens []string `json:"invalidTokens,omitempty"`
+
+	// Warnings contains the problems that are likely to make an audit of the
+	// file meaningless, like a file without any classes, or syntax errors
+	// that cause rules to be skipped.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Valid reports whether the file was validated without warnings.
+func (r CSSReport) Valid() bool {
+	return len(r.Warnings) == 0
+}
+
+// ValidateCSS parses the CSS file at the given path and reports the classes
+// that would be audited, without crawling a website. Use it to catch bad
+// inputs, like the wrong file or a truncated build output, before spending
+// time on a crawl. An error is only returned if the file cannot be read;
+// problems with its contents are reported as warnings.
+func ValidateCSS(path string) (CSSReport, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return CSSReport{}, err
+	}
+	css := string(b)
+
+	report := CSSReport{Path: path}
+
+	classes, err := ExtractClasses(css)
+	if err != nil {
+		return CSSReport{}, fmt.Errorf("extract classes: %w", err)
+	}
+	report.Classes = len(classes)
+	report.Sample = classes[:min(len(classes), cssReportSampleSize)]
+	if report.Sample == nil {
+		report.Sample = []string{}
+	}
+
+	sheet := parseStylesheet(css)
+	report.Rules = len(sheet.rules)
+
+	for _, rule := range sheet.rules {
+		for _, selector := range rule.selectors {
+			blanked, _ := blankStrings(selector)
+			for _, token := range classTokenRE.FindAllString(blanked, -1) {
+				if _, ok := classFromToken(token); !ok {
+					report.InvalidTokens = append(report.InvalidTokens, token)
+				}
+			}
+		}
+	}
+	slices.Sort(report.InvalidTokens)
+	report.InvalidTokens = slices.Compact(report.InvalidTokens)
+
+	if report.Classes == 0 {
+		report.Warnings = append(report.Warnings, "no classes found; check that the file is the CSS of the website and not, e.g., an error page or a script")
+	}
+
+	stripped := stripComments(css)
+	if _, offset := blankStrings(stripped); offset >= 0 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("unterminated string at line %d", strings.Count(stripped[:offset], "\n")+1))
+	}
+	if open := unbalancedBraces(stripped); open > 0 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%d unclosed block(s); the rules after the first unclosed block may be skipped", open))
+	} else if open < 0 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("%d unexpected \"}\"", -open))
+	}
+
+	return report, nil
+}
+
+// unbalancedBraces returns the number of blocks of the CSS that are not
+// closed, or the negated number of closing braces without an opening brace.
+// Braces within strings and escapes are ignored.
+func unbalancedBraces(css string) int {
+	blanked, _ := blankStrings(css)
+
+	depth, unexpected := 0, 0
+	for i := 0; i < len(blanked); i++ {
+		switch blanked[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				unexpected++
+				continue
+			}
+			depth--
+		}
+	}
+
+	if unexpected > 0 {
+		return -unexpected
+	}
+	return depth
+}
@@ -0,0 +1,102 @@
+package siteperf
+
+import (
+	"slices"
+	"strings"
+)
+
+// SplitVariants splits a utility class into its variant prefixes and its base
+// class, e.g. "md:hover:hidden" into ["md", "hover"] and "hidden". Colons
+// within brackets or parentheses, like in "bg-[url(a:b)]" or the arbitrary
+// variant "[&:nth-child(3)]:underline", do not separate variants. A class
+// without variants is returned as its own base.
+func SplitVariants(class string) (variants []string, base string) {
+	depth, start := 0, 0
+	for i := 0; i < len(class); i++ {
+		switch class[i] {
+		case '\\':
+			i++
+		case '[', '(':
+			depth++
+		case ']', ')':
+			depth--
+		case ':':
+			if depth == 0 {
+				variants = append(variants, class[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return variants, class[start:]
+}
+
+// VariantUsage is the usage of a base class and its variants (see
+// [SplitVariants]).
+type VariantUsage struct {
+	// Base is the base class, e.g. "hidden".
+	Base string `json:"base"`
+
+	// BaseDefined and BaseUsed report whether the base class itself is
+	// defined and used.
+	BaseDefined bool `json:"baseDefined"`
+	BaseUsed    bool `json:"baseUsed"`
+
+	// UsedVariants and UnusedVariants contain the defined variants of the
+	// base class, e.g. "md:hidden", that are used and unused, sorted by
+	// name.
+	UsedVariants   []string `json:"usedVariants"`
+	UnusedVariants []string `json:"unusedVariants"`
+}
+
+// AnalyzeVariants groups the defined classes that have variants by their base
+// class and reports which of them are used according to the given used-class
+// counts. Only base classes with at least one defined variant are returned,
+// sorted by base class. This reveals, for example, responsive variants like
+// "lg:hidden" that are generated but never used while "hidden" is, or
+// variants that are used although the base class never is.
+func AnalyzeVariants(defined []string, used map[string]int) []VariantUsage {
+	defined = unique(defined)
+
+	isDefined := make(map[string]bool, len(defined))
+	for _, class := range defined {
+		isDefined[class] = true
+	}
+
+	groups := make(map[string]*VariantUsage)
+	for _, class := range defined {
+		variants, base := SplitVariants(class)
+		if len(variants) == 0 {
+			continue
+		}
+
+		g, ok := groups[base]
+		if !ok {
+			g = &VariantUsage{
+				Base:           base,
+				BaseDefined:    isDefined[base],
+				BaseUsed:       used[base] > 0,
+				UsedVariants:   make([]string, 0),
+				UnusedVariants: make([]string, 0),
+			}
+			groups[base] = g
+		}
+
+		if used[class] > 0 {
+			g.UsedVariants = append(g.UsedVariants, class)
+		} else {
+			g.UnusedVariants = append(g.UnusedVariants, class)
+		}
+	}
+
+	out := make([]VariantUsage, 0, len(groups))
+	for _, g := range groups {
+		slices.Sort(g.UsedVariants)
+		slices.Sort(g.UnusedVariants)
+		out = append(out, *g)
+	}
+	slices.SortFunc(out, func(a, b VariantUsage) int {
+		return strings.Compare(a.Base, b.Base)
+	})
+
+	return out
+}
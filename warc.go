@@ -0,0 +1,287 @@
+package siteperf
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// warcWriter appends WARC records to a file. The records of each page are
+// appended at once, so that the records of concurrently crawled pages are not
+// interleaved. A warcWriter is safe for concurrent use.
+type warcWriter struct {
+	mux  sync.Mutex
+	path string
+}
+
+// warcRecord is a single record of a WARC file.
+type warcRecord struct {
+	id          string
+	typ         string
+	targetURI   string
+	date        time.Time
+	contentType string
+	concurrent  string
+	block       []byte
+}
+
+// write appends the given records to the WARC file. If the file is new, a
+// warcinfo record is written first.
+func (w *warcWriter) write(records []warcRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if stat.Size() == 0 {
+		writeWARCRecord(&buf, warcRecord{
+			id:          newWARCRecordID(),
+			typ:         "warcinfo",
+			date:        time.Now(),
+			contentType: "application/warc-fields",
+			block:       []byte("software: siteperf\r\nformat: WARC File Format 1.1\r\n"),
+		})
+	}
+	for _, rec := range records {
+		writeWARCRecord(&buf, rec)
+	}
+
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+func writeWARCRecord(buf *bytes.Buffer, rec warcRecord) {
+	buf.WriteString("WARC/1.1\r\n")
+	fmt.Fprintf(buf, "WARC-Type: %s\r\n", rec.typ)
+	fmt.Fprintf(buf, "WARC-Record-ID: %s\r\n", rec.id)
+	fmt.Fprintf(buf, "WARC-Date: %s\r\n", rec.date.UTC().Format(time.RFC3339))
+	if rec.targetURI != "" {
+		fmt.Fprintf(buf, "WARC-Target-URI: %s\r\n", rec.targetURI)
+	}
+	if rec.concurrent != "" {
+		fmt.Fprintf(buf, "WARC-Concurrent-To: %s\r\n", rec.concurrent)
+	}
+	fmt.Fprintf(buf, "Content-Type: %s\r\n", rec.contentType)
+	fmt.Fprintf(buf, "Content-Length: %d\r\n", len(rec.block))
+	buf.WriteString("\r\n")
+	buf.Write(rec.block)
+	buf.WriteString("\r\n\r\n")
+}
+
+func newWARCRecordID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("<urn:uuid:%x-%x-%x-%x-%x>", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// networkRecorder records the HTTP exchanges of a page using the network
+// events of the browser.
+type networkRecorder struct {
+	mux       sync.Mutex
+	exchanges []*networkExchange
+	current   map[proto.NetworkRequestID]*networkExchange
+	stop      func()
+}
+
+// networkExchange is a single request and its response. Redirects result in
+// one exchange per hop.
+type networkExchange struct {
+	id       proto.NetworkRequestID
+	date     time.Time
+	request  *proto.NetworkRequest
+	response *proto.NetworkResponse
+	redirect bool
+	finished bool
+}
+
+// recordNetwork starts recording the HTTP exchanges of the page. The returned
+// recorder must be stopped when the page is no longer used.
+func recordNetwork(page *rod.Page) *networkRecorder {
+	page, cancel := page.WithCancel()
+	r := &networkRecorder{
+		current: make(map[proto.NetworkRequestID]*networkExchange),
+		stop:    cancel,
+	}
+
+	wait := page.EachEvent(func(e *proto.NetworkRequestWillBeSent) {
+		r.mux.Lock()
+		defer r.mux.Unlock()
+
+		if prev, ok := r.current[e.RequestID]; ok && e.RedirectResponse != nil {
+			prev.response = e.RedirectResponse
+			prev.redirect = true
+			prev.finished = true
+		}
+
+		ex := &networkExchange{id: e.RequestID, date: time.Now(), request: e.Request}
+		r.current[e.RequestID] = ex
+		r.exchanges = append(r.exchanges, ex)
+	}, func(e *proto.NetworkResponseReceived) {
+		r.mux.Lock()
+		defer r.mux.Unlock()
+
+		if ex, ok := r.current[e.RequestID]; ok {
+			ex.response = e.Response
+		}
+	}, func(e *proto.NetworkLoadingFinished) {
+		r.mux.Lock()
+		defer r.mux.Unlock()
+
+		if ex, ok := r.current[e.RequestID]; ok {
+			ex.finished = true
+		}
+	})
+	go wait()
+
+	return r
+}
+
+// records returns the WARC records of the recorded HTTP exchanges. Only
+// exchanges over HTTP(S) with a response are included. The response bodies
+// are read from the page, which therefore must still be open.
+func (r *networkRecorder) records(page *rod.Page) []warcRecord {
+	r.mux.Lock()
+	exchanges := slices.Clone(r.exchanges)
+	r.mux.Unlock()
+
+	var out []warcRecord
+	for _, ex := range exchanges {
+		if ex.request == nil || ex.response == nil || !ex.finished {
+			continue
+		}
+		if u, err := url.Parse(ex.request.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			continue
+		}
+
+		var body []byte
+		if !ex.redirect {
+			res, err := proto.NetworkGetResponseBody{RequestID: ex.id}.Call(page)
+			if err == nil {
+				body = []byte(res.Body)
+				if res.Base64Encoded {
+					body, _ = base64.StdEncoding.DecodeString(res.Body)
+				}
+			}
+		}
+
+		response := warcRecord{
+			id:          newWARCRecordID(),
+			typ:         "response",
+			targetURI:   ex.request.URL,
+			date:        ex.date,
+			contentType: "application/http;msgtype=response",
+			block:       httpResponseBlock(ex.response, body),
+		}
+		out = append(out, response, warcRecord{
+			id:          newWARCRecordID(),
+			typ:         "request",
+			targetURI:   ex.request.URL,
+			date:        ex.date,
+			contentType: "application/http;msgtype=request",
+			concurrent:  response.id,
+			block:       httpRequestBlock(ex.request),
+		})
+	}
+
+	return out
+}
+
+func httpRequestBlock(req *proto.NetworkRequest) []byte {
+	var buf bytes.Buffer
+
+	target, host := req.URL, ""
+	if u, err := url.Parse(req.URL); err == nil {
+		target, host = u.RequestURI(), u.Host
+	}
+
+	fmt.Fprintf(&buf, "%s %s HTTP/1.1\r\n", req.Method, target)
+	// The browser does not report the Host header of HTTP/2 requests.
+	if !hasHeader(req.Headers, "Host") && host != "" {
+		fmt.Fprintf(&buf, "Host: %s\r\n", host)
+	}
+	writeHTTPHeaders(&buf, req.Headers, nil)
+	buf.WriteString("\r\n")
+	buf.WriteString(req.PostData)
+
+	return buf.Bytes()
+}
+
+func httpResponseBlock(res *proto.NetworkResponse, body []byte) []byte {
+	var buf bytes.Buffer
+
+	text := res.StatusText
+	if text == "" {
+		text = http.StatusText(res.Status)
+	}
+	fmt.Fprintf(&buf, "HTTP/1.1 %d %s\r\n", res.Status, text)
+
+	// The body is recorded as decoded by the browser, so the original
+	// encoding and length headers no longer apply.
+	writeHTTPHeaders(&buf, res.Headers, map[string]bool{
+		"content-encoding":  true,
+		"content-length":    true,
+		"transfer-encoding": true,
+	})
+	fmt.Fprintf(&buf, "Content-Length: %d\r\n", len(body))
+	buf.WriteString("\r\n")
+	buf.Write(body)
+
+	return buf.Bytes()
+}
+
+func writeHTTPHeaders(buf *bytes.Buffer, headers proto.NetworkHeaders, skip map[string]bool) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		if !skip[strings.ToLower(name)] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		// Multiple values of the same header are separated by line breaks.
+		for _, value := range strings.Split(headers[name].Str(), "\n") {
+			fmt.Fprintf(buf, "%s: %s\r\n", name, value)
+		}
+	}
+}
+
+func hasHeader(headers proto.NetworkHeaders, name string) bool {
+	for h := range headers {
+		if strings.EqualFold(h, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package siteperf
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// FindUnusedWeighted works like [Finder.FindUnused], but uses the weighted
+// usage scores of the classes to decide whether a class is unused. The score of
+// a class is the sum of the weights of all crawled pages it was found on (see
+// [WithPageWeights]). Classes with a score below the given threshold are
+// considered effectively unused and are included in the returned slice, in the
+// same order as they were provided.
+func (f *Finder) FindUnusedWeighted(ctx context.Context, classes []string, threshold float64) ([]string, error) {
+	result, err := f.crawl(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("find used classes: %w", err)
+	}
+
+	return f.unusedWeighted(result, classes, threshold), nil
+}
+
+// unusedWeighted returns the classes whose weighted usage score within the
+// result of a crawl is below the threshold. Like [Finder.FindUnusedFromUsed],
+// it never returns ignored classes (see [WithIgnoreClasses]).
+func (f *Finder) unusedWeighted(result *crawlResult, classes []string, threshold float64) []string {
+	return unusedBelow(f, f.weightedUsage(result), classes, threshold)
+}
+
+// weightedUsage computes the weighted usage score of every class found during
+// the crawl. Each page contributes its weight once to the score of every class
+// it uses, regardless of how many elements on the page use the class.
+func (f *Finder) weightedUsage(result *crawlResult) map[string]float64 {
+	scores := make(map[string]float64)
+	for _, page := range result.pages {
+		weight := f.pageWeight(page.url)
+		for _, class := range page.classes {
+			if class.count > 0 {
+				scores[class.class] += weight
+			}
+		}
+	}
+	return scores
+}
+
+// pageWeight returns the configured weight of the page with the given URL. The
+// weight is looked up by the full URL first, and by the path of the URL
+// second. Pages without a configured weight have a weight of 1.
+func (f *Finder) pageWeight(pageUrl string) float64 {
+	if w, ok := f.pageWeights[pageUrl]; ok {
+		return w
+	}
+	if u, err := url.Parse(pageUrl); err == nil {
+		if w, ok := f.pageWeights[u.Path]; ok {
+			return w
+		}
+	}
+	return 1
+}
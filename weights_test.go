@@ -0,0 +1,51 @@
+package siteperf
+
+import (
+	"slices"
+	"testing"
+)
+
+// weightedResult is the result of a crawl of a home page with a weight of 10
+// and a rarely visited page with a weight of 0.5.
+var weightedResult = &crawlResult{pages: []pageResult{
+	{url: "https://example.com/", classes: []usedClass{{class: "Btn", count: 2}, {class: "nav", count: 1}}},
+	{url: "https://example.com/legal", classes: []usedClass{{class: "fine-print", count: 4}}},
+}}
+
+func TestFinder_unusedWeighted(t *testing.T) {
+	weights := WithPageWeights(map[string]float64{"/": 10, "/legal": 0.5})
+	classes := []string{"btn", "Btn", "nav", "fine-print", "modal"}
+
+	tests := []struct {
+		name string
+		opts []Option
+		want []string
+	}{
+		{
+			name: "case-sensitive",
+			want: []string{"btn", "fine-print", "modal"},
+		},
+		{
+			name: "case-insensitive prefix",
+			opts: []Option{WithCaseInsensitivePrefixes([]string{"btn"})},
+			want: []string{"fine-print", "modal"},
+		},
+		{
+			name: "ignored classes",
+			opts: []Option{WithIgnoreClasses("fine-*", "modal")},
+			want: []string{"btn"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := New("https://example.com", 0, append([]Option{weights}, tt.opts...)...)
+			if err != nil {
+				t.Fatalf("New() failed: %v", err)
+			}
+			if got := f.unusedWeighted(weightedResult, classes, 1); !slices.Equal(got, tt.want) {
+				t.Errorf("unusedWeighted() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}